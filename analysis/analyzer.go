@@ -5,9 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
-	"regexp"
 	"slices"
-	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -53,9 +51,12 @@ type Analyzer struct {
 	Category    Category
 	Severity    Severity
 	Language    Language
-	Requires    []*Analyzer
-	Run         func(*Pass) (any, error)
-	ResultType  reflect.Type
+	// (optional) Metadata has the CWE IDs, OWASP categories, references and other
+	// details of the checker, which are copied to the issues it raises
+	Metadata   Metadata
+	Requires   []*Analyzer
+	Run        func(*Pass) (any, error)
+	ResultType reflect.Type
 }
 
 type Pass struct {
@@ -64,20 +65,16 @@ type Pass struct {
 	Files       []*ParseResult
 	ResultOf    map[*Analyzer]any
 	Report      func(*Pass, *sitter.Node, string)
+	// ReportWithFixes reports an issue like Report, with fixes suggested for it.
+	// Fixes with overlapping edits, or that leave syntax errors in the file, are dropped.
+	ReportWithFixes func(*Pass, *sitter.Node, string, ...SuggestedFix)
+	// ReportRange reports an issue on a node like ReportWithFixes, with the range
+	// of the issue narrowed to a part of the node, like its first line.
+	ReportRange func(*Pass, *sitter.Node, sitter.Range, string, ...SuggestedFix)
 	// TODO (opt): the cache should ideally not be stored in-memory
 	ResultCache map[*Analyzer]map[*ParseResult]any
 }
 
-// for caching the skipcq comments
-type SkipComment struct {
-	// the line number for the skipcq comment
-	CommentLine int
-	// the entire text of the skipcq comment
-	CommentText string
-	// (optional) name of the checker for targetted skip
-	CheckerIds []string
-}
-
 func walkTree(node *sitter.Node, f func(*sitter.Node)) {
 	f(node)
 
@@ -116,16 +113,18 @@ func findAnalyzers(analyzer *Analyzer) []*Analyzer {
 }
 
 func RunAnalyzers(path string, analyzers []*Analyzer, fileFilter func(string) bool) ([]*Issue, error) {
-	raisedIssues := []*Issue{}
-	langAnalyzerMap := make(map[Language][]*Analyzer)
-
-	for _, analyzer := range analyzers {
-		langAnalyzerMap[analyzer.Language] = append(langAnalyzerMap[analyzer.Language], findAnalyzers(analyzer)...)
+	files, err := ParseFiles(path, fileFilter)
+	if err != nil {
+		return []*Issue{}, err
 	}
 
-	trees := make(map[Language][]*ParseResult)
-	fileSkipInfo := make(map[string][]*SkipComment)
+	return RunAnalyzersOnFiles(path, files, analyzers)
+}
 
+// ParseFiles parses all files of a supported language under path that pass fileFilter.
+// A nil fileFilter accepts every file.
+func ParseFiles(path string, fileFilter func(string) bool) ([]*ParseResult, error) {
+	var files []*ParseResult
 	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // continue to the next file
@@ -150,34 +149,88 @@ func RunAnalyzers(path string, analyzers []*Analyzer, fileFilter func(string) bo
 			return nil
 		}
 
-		fileSkipInfo[file.FilePath] = GatherSkipInfo(file)
-
-		trees[file.Language] = append(trees[file.Language], file)
-
+		files = append(files, file)
 		return nil
 	})
-	if err != nil {
-		return raisedIssues, err
+
+	return files, err
+}
+
+// RunAnalyzersOnFiles runs analyzers on files that have already been parsed, for
+// content that isn't read from disk, like the staged version of a file.
+// Fingerprints are computed from the file paths relative to root.
+func RunAnalyzersOnFiles(root string, files []*ParseResult, analyzers []*Analyzer) ([]*Issue, error) {
+	raisedIssues := []*Issue{}
+	langAnalyzerMap := make(map[Language][]*Analyzer)
+
+	for _, analyzer := range analyzers {
+		langAnalyzerMap[analyzer.Language] = append(langAnalyzerMap[analyzer.Language], findAnalyzers(analyzer)...)
+	}
+
+	// code embedded in the files is only parsed for the languages that have analyzers
+	var injections []Injection
+	for _, injection := range DefaultInjections {
+		if len(langAnalyzerMap[injection.Target]) > 0 {
+			injections = append(injections, injection)
+		}
+	}
+
+	trees := make(map[Language][]*ParseResult)
+	for _, file := range files {
+		trees[file.Language] = append(trees[file.Language], file)
+		for _, injected := range InjectedFiles(file, injections) {
+			trees[injected.Language] = append(trees[injected.Language], injected)
+		}
 	}
 
-	reportFunc := func(pass *Pass, node *sitter.Node, message string) {
+	reportFunc := func(pass *Pass, node *sitter.Node, rng *sitter.Range, message string, fixes ...SuggestedFix) {
+		var validFixes []SuggestedFix
+		for _, fix := range fixes {
+			if ValidateFix(pass.FileContext, fix) == nil {
+				validFixes = append(validFixes, fix)
+			}
+		}
+
 		raisedIssue := &Issue{
 			Id:       &pass.Analyzer.Name,
 			Node:     node,
 			Message:  message,
 			Filepath: pass.FileContext.FilePath,
+			Fingerprint: Fingerprint(
+				pass.Analyzer.Name,
+				relativeIssuePath(root, pass.FileContext.FilePath),
+				node,
+				pass.FileContext.Source,
+			),
+			Fixes:    validFixes,
+			Metadata: pass.Analyzer.Metadata,
+
+			rng: rng,
 		}
 
-		skipLines := fileSkipInfo[pass.FileContext.FilePath]
-		if !ContainsSkipcq(skipLines, raisedIssue) {
+		skipComments := matchingSkipcqs(pass.FileContext.SkipComments(), raisedIssue)
+		if len(skipComments) == 0 {
 			raisedIssues = append(raisedIssues, raisedIssue)
+			return
+		}
+
+		for _, comment := range skipComments {
+			comment.Suppressed = append(comment.Suppressed, raisedIssue)
 		}
 	}
 
 	for lang, analyzers := range langAnalyzerMap {
 		pass := &Pass{
-			Files:       trees[lang],
-			Report:      reportFunc,
+			Files: trees[lang],
+			Report: func(pass *Pass, node *sitter.Node, message string) {
+				reportFunc(pass, node, nil, message)
+			},
+			ReportWithFixes: func(pass *Pass, node *sitter.Node, message string, fixes ...SuggestedFix) {
+				reportFunc(pass, node, nil, message, fixes...)
+			},
+			ReportRange: func(pass *Pass, node *sitter.Node, rng sitter.Range, message string, fixes ...SuggestedFix) {
+				reportFunc(pass, node, &rng, message, fixes...)
+			},
 			ResultOf:    make(map[*Analyzer]any),
 			ResultCache: make(map[*Analyzer]map[*ParseResult]any),
 		}
@@ -205,6 +258,7 @@ func RunAnalyzers(path string, analyzers []*Analyzer, fileFilter func(string) bo
 		}
 	}
 
+	disambiguateFingerprints(raisedIssues)
 	return raisedIssues, nil
 }
 
@@ -212,6 +266,8 @@ func ReportIssues(issues []*Issue, format string) ([]byte, error) {
 	switch format {
 	case "json":
 		return reportJSON(issues)
+	case "sarif":
+		return reportSARIF(issues)
 	case "text":
 		return reportText(issues)
 	default:
@@ -244,101 +300,3 @@ func reportText(issues []*Issue) ([]byte, error) {
 	}
 	return output, nil
 }
-
-// cache all the skipcq comments from an ast
-func GatherSkipInfo(fileContext *ParseResult) []*SkipComment {
-	var skipLines []*SkipComment
-
-	commentIdentifier := GetEscapedCommentIdentifierFromPath(fileContext.FilePath)
-	pattern := fmt.Sprintf(`%s(?i).*?\bskipcq\b(?::(?:\s*(?P<issue_ids>([A-Za-z\-_0-9]*(?:,\s*)?)+))?)?`, commentIdentifier)
-
-	skipRegexp := regexp.MustCompile(pattern)
-
-	query, err := sitter.NewQuery([]byte("(comment) @skipcq"), fileContext.Language.
-		Grammar())
-
-	if err != nil {
-		return skipLines
-	}
-
-	cursor := sitter.NewQueryCursor()
-	cursor.Exec(query, fileContext.Ast)
-
-	// gather all skipcq comment lines in a single pass
-	for {
-		m, ok := cursor.NextMatch()
-		if !ok {
-			break
-		}
-
-		for _, capture := range m.Captures {
-			captureName := query.CaptureNameForId(capture.Index)
-			if captureName != "skipcq" {
-				continue
-			}
-
-			commentNode := capture.Node
-			commentLine := int(commentNode.StartPoint().Row)
-			commentText := commentNode.Content(fileContext.Source)
-
-			matches := skipRegexp.FindStringSubmatch(commentText)
-			if matches != nil {
-				issueIdsIdx := skipRegexp.SubexpIndex("issue_ids")
-				var checkerIds []string
-
-				if issueIdsIdx != -1 && issueIdsIdx < len(matches) && matches[issueIdsIdx] != "" {
-					issueIdsIdx := matches[issueIdsIdx]
-					idSlice := strings.Split(issueIdsIdx, ",")
-					for _, id := range idSlice {
-						trimmedId := strings.TrimSpace(id)
-						if trimmedId != "" {
-							checkerIds = append(checkerIds, trimmedId)
-						}
-					}
-				}
-
-				skipLines = append(skipLines, &SkipComment{
-					CommentLine: commentLine,
-					CommentText: commentText,
-					CheckerIds:  checkerIds, // will be empty for generic skipcq
-				})
-			}
-
-		}
-	}
-
-	return skipLines
-}
-
-func ContainsSkipcq(skipLines []*SkipComment, issue *Issue) bool {
-	if len(skipLines) == 0 {
-		return false
-	}
-
-	issueNode := issue.Node
-	nodeLine := int(issueNode.StartPoint().Row)
-	prevLine := nodeLine - 1
-
-	var checkerId string
-	if issue.Id != nil {
-		checkerId = *issue.Id
-	}
-
-	for _, comment := range skipLines {
-		if comment.CommentLine != nodeLine && comment.CommentLine != prevLine {
-			continue
-		}
-
-		if len(comment.CheckerIds) == 0 {
-			return true
-		}
-
-		for _, id := range comment.CheckerIds {
-			if checkerId == id {
-				return true
-			}
-		}
-	}
-
-	return false
-}
@@ -201,3 +201,268 @@ func TestSkipCqComment(t *testing.T) {
 		})
 	}
 }
+
+// badCallChecker reports all calls to a function named `bad`.
+func badCallChecker(pass *Pass) (interface{}, error) {
+	Preorder(pass, func(node *sitter.Node) {
+		if node.Type() != "call" && node.Type() != "call_expression" {
+			return
+		}
+
+		function := node.ChildByFieldName("function")
+		if function != nil && function.Content(pass.FileContext.Source) == "bad" {
+			pass.Report(pass, node, "Call to bad")
+		}
+	})
+
+	return nil, nil
+}
+
+func TestSkipCqScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		source   string
+		language Language
+		// the 1-indexed lines of the issues that are not suppressed
+		want []int
+	}{
+		{
+			name:     "skipcq-file",
+			filename: "app.py",
+			source:   "# skipcq-file: bad-call\nimport os\n\nbad()\n\ndef f():\n    bad()\n",
+			language: LangPy,
+			want:     nil,
+		},
+		{
+			name:     "skipcq-file for another checker",
+			filename: "app.py",
+			source:   "# skipcq-file: other-checker\nbad()\n",
+			language: LangPy,
+			want:     []int{2},
+		},
+		{
+			name:     "skipcq-begin and skipcq-end",
+			filename: "app.js",
+			source:   "bad();\n// skipcq-begin: bad-call\nbad();\n\nbad();\n// skipcq-end\nbad();\n",
+			language: LangJs,
+			want:     []int{1, 7},
+		},
+		{
+			name:     "unterminated skipcq-begin",
+			filename: "app.py",
+			source:   "bad()\n# skipcq-begin\nbad()\nbad()\n",
+			language: LangPy,
+			want:     []int{1},
+		},
+		{
+			name:     "skipcq above a multi-line call",
+			filename: "app.js",
+			source:   "// skipcq: bad-call\nfoo(\n  1,\n  bad(),\n);\nbad();\n",
+			language: LangJs,
+			want:     []int{6},
+		},
+		{
+			name:     "skipcq above a function",
+			filename: "app.py",
+			source:   "# skipcq\ndef f():\n    bad()\n    bad()\n\nbad()\n",
+			language: LangPy,
+			want:     []int{6},
+		},
+		{
+			name:     "skipcq above a statement in a block",
+			filename: "app.py",
+			source:   "if x:\n    # skipcq\n    foo(\n        bad())\n    bad()\n",
+			language: LangPy,
+			want:     []int{5},
+		},
+		{
+			name:     "skipcq after a multi-line call",
+			filename: "main.go",
+			source:   "package main\n\nfunc main() {\n\tfoo(\n\t\tbad(),\n\t) // skipcq: bad-call\n\tbad()\n}\n",
+			language: LangGo,
+			want:     []int{7},
+		},
+		{
+			name:     "skipcq above a multi-line call in Go",
+			filename: "main.go",
+			source:   "package main\n\nfunc main() {\n\t// skipcq: bad-call\n\tfoo(\n\t\tbad(),\n\t)\n\tbad()\n}\n",
+			language: LangGo,
+			want:     []int{8},
+		},
+		{
+			name:     "expired skipcq",
+			filename: "app.py",
+			source:   "bad()  # skipcq: bad-call until=2001-01-01\nbad()  # skipcq: bad-call until=2999-01-01\nbad()  # skipcq: bad-call until=someday\n",
+			language: LangPy,
+			want:     []int{1, 3},
+		},
+		{
+			name:     "skipcq separated by a blank line",
+			filename: "app.js",
+			source:   "// skipcq\n\nfoo(\n  bad(),\n);\n",
+			language: LangJs,
+			want:     []int{4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseTestFile(t, tt.filename, tt.source, tt.language)
+			analyzer := &Analyzer{
+				Name:     "bad-call",
+				Language: tt.language,
+				Run:      badCallChecker,
+			}
+
+			issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{analyzer})
+			require.NoError(t, err)
+
+			var lines []int
+			for _, issue := range issues {
+				lines = append(lines, int(issue.Range().StartPoint.Row)+1)
+			}
+			assert.Equal(t, tt.want, lines)
+		})
+	}
+}
+
+func TestSkipCqAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		filename   string
+		source     string
+		language   Language
+		checkerIds []string
+		reason     string
+		owner      string
+		until      string
+	}{
+		{
+			name:       "reason and expiry",
+			filename:   "main.go",
+			source:     "package main\n\n// skipcq: go_tls_insecure reason=\"test cert\" until=2027-01-01\nvar x = 1\n",
+			language:   LangGo,
+			checkerIds: []string{"go_tls_insecure"},
+			reason:     "test cert",
+			until:      "2027-01-01",
+		},
+		{
+			name:       "owner and multiple checkers",
+			filename:   "app.py",
+			source:     "x = 1  # skipcq: avoid-assert, csrf-exempt owner=@security reason='legacy code'\n",
+			language:   LangPy,
+			checkerIds: []string{"avoid-assert", "csrf-exempt"},
+			reason:     "legacy code",
+			owner:      "@security",
+		},
+		{
+			name:     "attributes without checkers",
+			filename: "app.js",
+			source:   "// skipcq-file reason=generated\nlet x = 1;\n",
+			language: LangJs,
+			reason:   "generated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseTestFile(t, tt.filename, tt.source, tt.language)
+			skipComments := GatherSkipInfo(parsed)
+			require.Len(t, skipComments, 1)
+
+			comment := skipComments[0]
+			assert.Equal(t, tt.checkerIds, comment.CheckerIds)
+			assert.Equal(t, tt.reason, comment.Reason)
+			assert.Equal(t, tt.owner, comment.Owner)
+			assert.Equal(t, tt.until, comment.Until)
+		})
+	}
+}
+
+func TestSkipCqCommentStyles(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		source   string
+		language Language
+		// the 0-indexed lines of the skipcq comments, and their checker IDs
+		want map[int][]string
+	}{
+		{
+			name:     "block comments in javascript",
+			filename: "app.js",
+			source:   "/* skipcq: no-eval */\neval(x);\nfoo(); /* skipcq */\n",
+			language: LangJs,
+			want:     map[int][]string{0: {"no-eval"}, 2: nil},
+		},
+		{
+			name:     "line and block comment nodes in java",
+			filename: "App.java",
+			source:   "class App {\n  // skipcq: java-a\n  int a;\n  /* skipcq: java-b */\n  int b;\n}\n",
+			language: LangJava,
+			want:     map[int][]string{1: {"java-a"}, 3: {"java-b"}},
+		},
+		{
+			name:     "multi-line block comment in go",
+			filename: "main.go",
+			source:   "package main\n\n/*\n * skipcq-file: go-a, go-b\n */\n",
+			language: LangGo,
+			want:     map[int][]string{2: {"go-a", "go-b"}},
+		},
+		{
+			name:     "elm comments",
+			filename: "Main.elm",
+			source:   "module Main exposing (..)\n\n-- skipcq: elm-a\nx = 1\n\n{- skipcq: elm-b -}\ny = 2\n",
+			language: LangElm,
+			want:     map[int][]string{2: {"elm-a"}, 5: {"elm-b"}},
+		},
+		{
+			name:     "lua block comment",
+			filename: "app.lua",
+			source:   "--[[ skipcq: lua-a ]]\nx = 1\n-- skipcq: lua-b\ny = 2\n",
+			language: LangLua,
+			want:     map[int][]string{0: {"lua-a"}, 2: {"lua-b"}},
+		},
+		{
+			name:     "html comment",
+			filename: "index.html",
+			source:   "<!-- skipcq: html-a -->\n<p>x</p>\n",
+			language: LangHtml,
+			want:     map[int][]string{0: {"html-a"}},
+		},
+		{
+			name:     "ocaml comment",
+			filename: "main.ml",
+			source:   "(* skipcq: ml-a *)\nlet x = 1\n",
+			language: LangOCaml,
+			want:     map[int][]string{0: {"ml-a"}},
+		},
+		{
+			name:     "ruby block comment",
+			filename: "app.rb",
+			source:   "=begin\nskipcq: rb-a\n=end\nx = 1\n",
+			language: LangRuby,
+			want:     map[int][]string{0: {"rb-a"}},
+		},
+		{
+			name:     "php hash and slash comments",
+			filename: "index.php",
+			source:   "<?php\n# skipcq: php-a\n$a = 1;\n// skipcq: php-b\n$b = 1;\n",
+			language: LangPhp,
+			want:     map[int][]string{1: {"php-a"}, 3: {"php-b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseTestFile(t, tt.filename, tt.source, tt.language)
+
+			got := map[int][]string{}
+			for _, comment := range GatherSkipInfo(parsed) {
+				got[comment.CommentLine] = comment.CheckerIds
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Code patterns are written as code in the language they match, where $X is a
+// metavariable that matches any node and ... matches any number of nodes.
+//
+// Metavariables and ellipses aren't valid syntax in most languages, so they are
+// replaced with placeholder identifiers before the pattern is parsed with the grammar
+// of the language. The parse tree is then compiled to a tree-sitter query with the
+// same structure, where the placeholders match any node.
+const (
+	metavariablePrefix  = "__globstar_mv_"
+	ellipsisPlaceholder = "__globstar_ellipsis__"
+)
+
+var (
+	metavariableRegexp     = regexp.MustCompile(`\$([A-Z_][A-Z0-9_]*)`)
+	metavariableNameRegexp = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+)
+
+// codePatternWrappers are the code that makes snippets valid in languages where
+// statements can't appear at the top level of a file, as a prefix and a suffix.
+// The snippet is parsed on its own first, then in each of the wrappers.
+var codePatternWrappers = map[Language][][2]string{
+	LangGo: {{"package p\nfunc __globstar__() {\n", "\n}\n"}},
+	LangJava: {
+		{"class __Globstar__ {\nvoid __globstar__() {\n", "\n}\n}\n"},
+		{"class __Globstar__ {\nvoid __globstar__() {\n", ";\n}\n}\n"},
+		{"class __Globstar__ {\n", "\n}\n"},
+	},
+	LangCsharp: {
+		{"class __Globstar__ {\nvoid __globstar__() {\n", "\n}\n}\n"},
+		{"class __Globstar__ {\nvoid __globstar__() {\n", ";\n}\n}\n"},
+		{"class __Globstar__ {\n", "\n}\n"},
+	},
+	LangRust:   {{"fn __globstar__() {\n", "\n}\n"}},
+	LangPhp:    {{"<?php\n", "\n"}},
+	LangKotlin: {{"fun __globstar__() {\n", "\n}\n"}},
+	LangScala:  {{"object __Globstar__ {\n", "\n}\n"}},
+	LangSwift:  {{"func __globstar__() {\n", "\n}\n"}},
+}
+
+// tokens that are left out of the compiled query, so that ellipses can stand for
+// any number of nodes separated by them
+var codePatternPunctuation = map[string]bool{
+	"(": true, ")": true, "[": true, "]": true, "{": true, "}": true,
+	",": true, ";": true, ":": true, ".": true, "\n": true,
+}
+
+// CompileCodePattern compiles a code pattern in lang to a tree-sitter query. The node
+// matching the whole pattern is captured as @name, and each metavariable $X as @X.
+func CompileCodePattern(code string, lang Language, name string) (string, error) {
+	grammar := lang.Grammar()
+	if grammar == nil {
+		return "", fmt.Errorf("code patterns are not supported for %s", lang)
+	}
+
+	snippet := replacePlaceholders(strings.TrimSpace(code))
+	if snippet == "" {
+		return "", fmt.Errorf("empty code pattern")
+	}
+
+	root, source, err := parseCodePattern(snippet, lang, grammar)
+	if err != nil {
+		return "", err
+	}
+
+	c := &codePatternCompiler{source: source, metavariables: map[string]int{}}
+	pattern := c.compile(root)
+
+	query := "(" + pattern
+	for _, predicate := range c.predicates {
+		query += "\n  " + predicate
+	}
+	query += ") @" + name
+
+	if _, err := sitter.NewQuery([]byte(query), grammar); err != nil {
+		return "", fmt.Errorf("failed to compile code pattern to a valid query: %w\n%s", err, query)
+	}
+
+	return query, nil
+}
+
+// replacePlaceholders replaces the metavariables and ellipses in code with placeholder identifiers.
+// An ellipsis followed by an identifier, like a spread in JavaScript, is left alone.
+func replacePlaceholders(code string) string {
+	code = metavariableRegexp.ReplaceAllString(code, metavariablePrefix+"$1")
+
+	var out strings.Builder
+	for i := 0; i < len(code); i++ {
+		if strings.HasPrefix(code[i:], "...") {
+			next := i + 3
+			if next >= len(code) || !isIdentifierByte(code[next]) {
+				out.WriteString(ellipsisPlaceholder)
+				i += 2
+				continue
+			}
+		}
+		out.WriteByte(code[i])
+	}
+
+	return out.String()
+}
+
+// isMetavariable reports whether text is the placeholder of a metavariable.
+func isMetavariable(text string) bool {
+	name, ok := strings.CutPrefix(text, metavariablePrefix)
+	return ok && metavariableNameRegexp.MatchString(name)
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// parseCodePattern parses snippet on its own, or in one of the wrappers of the language,
+// and returns the node of the pattern in the parse tree.
+func parseCodePattern(snippet string, lang Language, grammar *sitter.Language) (*sitter.Node, []byte, error) {
+	wrappers := append([][2]string{{"", ""}}, codePatternWrappers[lang]...)
+
+	for _, wrapper := range wrappers {
+		source := []byte(wrapper[0] + snippet + wrapper[1])
+		tree, err := sitter.ParseCtx(context.Background(), source, grammar)
+		if err != nil || tree.HasError() {
+			continue
+		}
+
+		start := uint32(len(wrapper[0]))
+		end := start + uint32(len(snippet))
+
+		var nodes []*sitter.Node
+		var collect func(node *sitter.Node)
+		collect = func(node *sitter.Node) {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				child := node.NamedChild(i)
+				if child.EndByte() <= start || child.StartByte() >= end || isCommentNode(child) {
+					continue
+				}
+
+				if child.StartByte() >= start && child.EndByte() <= end {
+					nodes = append(nodes, child)
+				} else {
+					collect(child)
+				}
+			}
+		}
+		collect(tree)
+
+		if len(nodes) != 1 {
+			return nil, nil, fmt.Errorf("code pattern must be a single expression, statement or declaration")
+		}
+
+		node := unwrapStatement(nodes[0], source)
+		text := node.Content(source)
+		if text == ellipsisPlaceholder || isMetavariable(text) {
+			return nil, nil, fmt.Errorf("code pattern can't be only a metavariable or an ellipsis")
+		}
+
+		return node, source, nil
+	}
+
+	return nil, nil, fmt.Errorf("code pattern is not valid %s code", lang)
+}
+
+// unwrapStatement returns the expression in an expression statement, so that
+// an expression pattern matches the expression anywhere, not only as a statement.
+func unwrapStatement(node *sitter.Node, source []byte) *sitter.Node {
+	for node.NamedChildCount() == 1 {
+		child := node.NamedChild(0)
+		text := strings.TrimSuffix(strings.TrimSpace(node.Content(source)), ";")
+		if strings.TrimSpace(text) != child.Content(source) {
+			break
+		}
+		node = child
+	}
+	return node
+}
+
+type codePatternCompiler struct {
+	source []byte
+	// the number of times each metavariable was seen
+	metavariables map[string]int
+	// the predicates on the captures of literals and repeated metavariables
+	predicates []string
+	literals   int
+}
+
+func (c *codePatternCompiler) compile(node *sitter.Node) string {
+	text := node.Content(c.source)
+
+	if isMetavariable(text) {
+		name := strings.TrimPrefix(text, metavariablePrefix)
+		c.metavariables[name]++
+		if c.metavariables[name] == 1 {
+			return "(_) @" + name
+		}
+
+		// a metavariable seen again must match the same code
+		capture := fmt.Sprintf("%s__%d", name, c.metavariables[name])
+		c.predicates = append(c.predicates, fmt.Sprintf("(#eq? @%s @%s)", name, capture))
+		return "(_) @" + capture
+	}
+
+	// "..." in a string matches any string
+	if strings.Contains(node.Type(), "string") && strings.Trim(text, "\"'`") == ellipsisPlaceholder {
+		return "(" + node.Type() + ")"
+	}
+
+	if node.NamedChildCount() == 0 {
+		capture := fmt.Sprintf("__lit%d", c.literals)
+		c.literals++
+		c.predicates = append(c.predicates, fmt.Sprintf("(#eq? @%s %s)", capture, quoteQueryString(text)))
+		return "(" + node.Type() + ") @" + capture
+	}
+
+	type item struct {
+		pattern string
+		named   bool
+		gap     bool
+	}
+
+	var items []item
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		field := node.FieldNameForChild(i)
+		prefix := ""
+		if field != "" {
+			prefix = field + ": "
+		}
+
+		if !child.IsNamed() {
+			token := child.Type()
+			if field == "" && codePatternPunctuation[token] {
+				continue
+			}
+			items = append(items, item{pattern: prefix + quoteQueryString(token)})
+			continue
+		}
+
+		if isCommentNode(child) {
+			continue
+		}
+
+		if child.Content(c.source) == ellipsisPlaceholder {
+			items = append(items, item{gap: true})
+			continue
+		}
+
+		items = append(items, item{pattern: prefix + c.compile(child), named: true})
+	}
+
+	// named children are anchored to each other and to the start and end of the
+	// node, so that the pattern only matches nodes with the same children, except
+	// where there are ellipses
+	var parts []string
+	for i, it := range items {
+		if it.gap {
+			continue
+		}
+
+		if it.named && (i == 0 || items[i-1].named) {
+			parts = append(parts, ".")
+		}
+		parts = append(parts, it.pattern)
+	}
+	if len(items) > 0 && items[len(items)-1].named {
+		parts = append(parts, ".")
+	}
+
+	return "(" + node.Type() + " " + strings.Join(parts, " ") + ")"
+}
+
+// quoteQueryString quotes s as a string in a tree-sitter query.
+func quoteQueryString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + replacer.Replace(s) + `"`
+}
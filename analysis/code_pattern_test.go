@@ -0,0 +1,135 @@
+package analysis
+
+import (
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    Language
+		pattern string
+		source  string
+		// the rows of the reported nodes, and the messages when there's a $X in the message
+		want     []int
+		messages []string
+		wantErr  bool
+	}{
+		{
+			name:     "metavariable",
+			lang:     LangPy,
+			pattern:  "eval($X)",
+			source:   "eval(code)\neval(a, b)\nevaluate(code)\neval()\n",
+			want:     []int{0},
+			messages: []string{"eval of code"},
+		},
+		{
+			name:    "repeated metavariable",
+			lang:    LangPy,
+			pattern: "$X == $X",
+			source:  "a == a\na == b\nf(x) == f(x)\n",
+			want:    []int{0, 2},
+		},
+		{
+			name:    "ellipsis in arguments",
+			lang:    LangPy,
+			pattern: "requests.get(..., verify=False, ...)",
+			source:  "requests.get(url, verify=False)\nrequests.get(url, timeout=1, verify=False, stream=True)\nrequests.get(url)\nrequests.get(url, verify=True)\n",
+			want:    []int{0, 1},
+		},
+		{
+			name:    "ellipsis as all arguments",
+			lang:    LangJs,
+			pattern: "eval(...)",
+			source:  "eval();\neval(a, b);\nx.eval(a);\n",
+			want:    []int{0, 1},
+		},
+		{
+			name:    "ellipsis in a string",
+			lang:    LangJs,
+			pattern: `document.write("...")`,
+			source:  "document.write(\"<p>\");\ndocument.write(html);\n",
+			want:    []int{0},
+		},
+		{
+			name:    "wrapped in a function",
+			lang:    LangGo,
+			pattern: "&tls.Config{..., InsecureSkipVerify: true, ...}",
+			source:  "package main\n\nvar a = &tls.Config{InsecureSkipVerify: true}\nvar b = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true}\nvar c = &tls.Config{InsecureSkipVerify: false}\n",
+			want:    []int{2, 3},
+		},
+		{
+			name:    "wrapped in a class",
+			lang:    LangJava,
+			pattern: "Runtime.getRuntime().exec($CMD)",
+			source:  "class A {\n  void run(String cmd) {\n    Runtime.getRuntime().exec(cmd);\n    Runtime.getRuntime().exec(cmd, env);\n  }\n}\n",
+			want:    []int{2},
+		},
+		{
+			name:    "invalid code",
+			lang:    LangPy,
+			pattern: "eval(",
+			wantErr: true,
+		},
+		{
+			name:    "more than one statement",
+			lang:    LangPy,
+			pattern: "a = 1\nb = 2",
+			wantErr: true,
+		},
+		{
+			name:    "only a metavariable",
+			lang:    LangPy,
+			pattern: "$X",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ana, _, err := FromYaml(Yaml{
+				Language:    tt.lang.String(),
+				Code:        "code-pattern",
+				Message:     "eval of $X",
+				CodePattern: tt.pattern,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parsed := parseTestFile(t, "app"+GetExtFromLanguage(tt.lang), tt.source, tt.lang)
+
+			var got []int
+			var messages []string
+			pass := &Pass{
+				Analyzer:    &ana,
+				FileContext: parsed,
+				Files:       []*ParseResult{parsed},
+				Report: func(pass *Pass, node *sitter.Node, message string) {
+					got = append(got, int(node.StartPoint().Row))
+					messages = append(messages, message)
+				},
+			}
+
+			_, err = ana.Run(pass)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			if tt.messages != nil {
+				assert.Equal(t, tt.messages, messages)
+			}
+		})
+	}
+}
+
+func TestCompileCodePatternExplain(t *testing.T) {
+	query, err := CompileCodePattern("$X == $X", LangPy, "same")
+	require.NoError(t, err)
+	assert.Equal(t, `((comparison_operator . (_) @X operators: "==" (_) @X__2 .)
+  (#eq? @X @X__2)) @same`, query)
+}
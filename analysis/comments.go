@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// CommentSyntax describes how comments are written in a language.
+type CommentSyntax struct {
+	// Line are the prefixes of line comments, like "//"
+	Line []string
+	// Block are the opening and closing delimiters of block comments, like {"/*", "*/"}
+	Block [][2]string
+}
+
+var (
+	cStyleComments = CommentSyntax{Line: []string{"//"}, Block: [][2]string{{"/*", "*/"}}}
+	hashComments   = CommentSyntax{Line: []string{"#"}}
+	htmlComments   = CommentSyntax{Block: [][2]string{{"<!--", "-->"}}}
+)
+
+var commentSyntaxes = map[Language]CommentSyntax{
+	LangPy:         hashComments,
+	LangJs:         cStyleComments,
+	LangTs:         cStyleComments,
+	LangTsx:        cStyleComments,
+	LangJava:       cStyleComments,
+	LangRuby:       {Line: []string{"#"}, Block: [][2]string{{"=begin", "=end"}}},
+	LangRust:       cStyleComments,
+	LangYaml:       hashComments,
+	LangCss:        {Block: [][2]string{{"/*", "*/"}}},
+	LangDockerfile: hashComments,
+	LangMarkdown:   htmlComments,
+	LangSql:        {Line: []string{"--"}, Block: [][2]string{{"/*", "*/"}}},
+	LangKotlin:     cStyleComments,
+	LangOCaml:      {Block: [][2]string{{"(*", "*)"}}},
+	LangLua:        {Line: []string{"--"}, Block: [][2]string{{"--[[", "]]"}}},
+	LangBash:       hashComments,
+	LangCsharp:     cStyleComments,
+	LangElixir:     hashComments,
+	LangElm:        {Line: []string{"--"}, Block: [][2]string{{"{-", "-}"}}},
+	LangGo:         cStyleComments,
+	LangGroovy:     cStyleComments,
+	LangHcl:        {Line: []string{"#", "//"}, Block: [][2]string{{"/*", "*/"}}},
+	LangHtml:       htmlComments,
+	LangPhp:        {Line: []string{"//", "#"}, Block: [][2]string{{"/*", "*/"}}},
+	LangScala:      cStyleComments,
+	LangSwift:      cStyleComments,
+}
+
+// CommentSyntax returns the line and block comment delimiters of the language.
+func (lang Language) CommentSyntax() CommentSyntax {
+	return commentSyntaxes[lang]
+}
+
+// CommentBody returns the text of a comment without its delimiters, and
+// whether text is a comment in this syntax at all.
+func (syntax CommentSyntax) CommentBody(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+
+	// block comments first, since their delimiters can start with a line comment prefix (like Lua's "--[[")
+	for _, block := range syntax.Block {
+		if strings.HasPrefix(text, block[0]) {
+			return strings.TrimSuffix(strings.TrimPrefix(text, block[0]), block[1]), true
+		}
+	}
+
+	for _, prefix := range syntax.Line {
+		if strings.HasPrefix(text, prefix) {
+			return strings.TrimPrefix(text, prefix), true
+		}
+	}
+
+	return "", false
+}
+
+// commentNodes returns the comment nodes in a file, in source order.
+// Grammars name them differently (comment, line_comment, block_comment...),
+// and the nodes some of them have for the parts of a comment are not returned.
+func commentNodes(file *ParseResult) []*sitter.Node {
+	var comments []*sitter.Node
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if isCommentNode(node) {
+			comments = append(comments, node)
+			return
+		}
+
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+
+	if file.Ast != nil {
+		walk(file.Ast)
+	}
+
+	return comments
+}
+
+// trimComment returns the text of a comment node, with the byte offset and line it starts on.
+// Some grammars (like Lua's) include the whitespace before a comment in its node,
+// which is left out.
+func trimComment(node *sitter.Node, source []byte) (string, uint32, int) {
+	text := node.Content(source)
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	leading := text[:len(text)-len(trimmed)]
+	return trimmed, node.StartByte() + uint32(len(leading)), int(node.StartPoint().Row) + strings.Count(leading, "\n")
+}
+
+func isCommentNode(node *sitter.Node) bool {
+	return strings.Contains(node.Type(), "comment")
+}
@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// scopeNodeRegexp matches the node types (across grammars) whose name
+// is used as the context of an issue raised inside them.
+var scopeNodeRegexp = regexp.MustCompile(`function|method|class|module|interface|struct|impl|trait`)
+
+// Fingerprint returns an identifier for an issue that stays the same when
+// the code around the issue moves or is re-formatted. It is computed from the
+// checker ID, the file path, the matched source text with whitespace removed and
+// the names of the enclosing functions and classes.
+//
+// Two issues raised by the same checker on identical code in the same scope share a
+// fingerprint. `RunAnalyzers` tells them apart with `disambiguateFingerprints`.
+func Fingerprint(checkerId string, filePath string, node *sitter.Node, source []byte) string {
+	h := sha256.New()
+	h.Write([]byte(checkerId))
+	h.Write([]byte{0})
+	h.Write([]byte(filepath.ToSlash(filePath)))
+	h.Write([]byte{0})
+	// an issue on the whole file, like a pattern missing from it, is identified
+	// by its checker and file, since any change to the file changes its source
+	if node != nil && node.Parent() != nil {
+		h.Write([]byte(normalizeSource(node.Content(source))))
+		h.Write([]byte{0})
+		h.Write([]byte(scopeContext(node, source)))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// normalizeSource strips all whitespace from a snippet of code.
+func normalizeSource(code string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// scopeContext returns the dot separated names of the named scopes enclosing node,
+// outermost first (e.g. "UserView.get").
+func scopeContext(node *sitter.Node, source []byte) string {
+	var names []string
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if !scopeNodeRegexp.MatchString(parent.Type()) {
+			continue
+		}
+
+		name := parent.ChildByFieldName("name")
+		if name == nil {
+			continue
+		}
+
+		names = append(names, name.Content(source))
+	}
+
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+
+	return strings.Join(names, ".")
+}
+
+// disambiguateFingerprints makes the fingerprints of issues that share one unique,
+// by mixing in the index of each occurrence in source order.
+// The first occurrence keeps its fingerprint.
+func disambiguateFingerprints(issues []*Issue) {
+	sorted := make([]*Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Fingerprint != "" {
+			sorted = append(sorted, issue)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Filepath != sorted[j].Filepath {
+			return sorted[i].Filepath < sorted[j].Filepath
+		}
+		if sorted[i].Node == nil || sorted[j].Node == nil {
+			return false
+		}
+		return sorted[i].Node.StartByte() < sorted[j].Node.StartByte()
+	})
+
+	occurrences := make(map[string]int)
+	for _, issue := range sorted {
+		base := issue.Fingerprint
+		n := occurrences[base]
+		occurrences[base] = n + 1
+		if n == 0 {
+			continue
+		}
+
+		sum := sha256.Sum256([]byte(base + ":" + strconv.Itoa(n)))
+		issue.Fingerprint = hex.EncodeToString(sum[:16])
+	}
+}
+
+// relativeIssuePath returns the path of a file relative to the analyzed root,
+// so that fingerprints don't depend on where the repository is checked out.
+func relativeIssuePath(root, filePath string) string {
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil || rel == "." {
+		return filepath.Base(filePath)
+	}
+	return rel
+}
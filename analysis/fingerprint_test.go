@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runNoAssert(t *testing.T, source string) []*Issue {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.py"), []byte(source), 0o644)
+	require.NoError(t, err)
+
+	analyzer := &Analyzer{
+		Name:     "no-assert",
+		Language: LangPy,
+		Run:      mockChecker,
+	}
+
+	issues, err := RunAnalyzers(dir, []*Analyzer{analyzer}, nil)
+	require.NoError(t, err)
+	return issues
+}
+
+func TestFingerprint(t *testing.T) {
+	original := runNoAssert(t, `
+def check(a, b):
+    assert a == b
+
+class Foo:
+    def check(self, a, b):
+        assert a == b
+`)
+	require.Len(t, original, 2)
+	assert.NotEmpty(t, original[0].Fingerprint)
+	assert.NotEqual(t, original[0].Fingerprint, original[1].Fingerprint, "different scopes should give different fingerprints")
+
+	moved := runNoAssert(t, `
+import os
+
+
+def check(a, b):
+    print(a)
+    assert a==b
+
+class Foo:
+    def check(self, a, b):
+        assert   a == b
+`)
+	require.Len(t, moved, 2)
+	assert.Equal(t, original[0].Fingerprint, moved[0].Fingerprint, "line moves and whitespace should not change the fingerprint")
+	assert.Equal(t, original[1].Fingerprint, moved[1].Fingerprint, "line moves and whitespace should not change the fingerprint")
+
+	duplicates := runNoAssert(t, `
+def check(a, b):
+    assert a == b
+    assert a == b
+`)
+	require.Len(t, duplicates, 2)
+	assert.Equal(t, original[0].Fingerprint, duplicates[0].Fingerprint, "the first occurrence should keep its fingerprint")
+	assert.NotEqual(t, duplicates[0].Fingerprint, duplicates[1].Fingerprint, "identical issues should get unique fingerprints")
+}
+
+func TestFingerprintWholeFile(t *testing.T) {
+	before := parseTestFile(t, "settings.py", "DEBUG = False\n", LangPy)
+	after := parseTestFile(t, "settings.py", "DEBUG = True\nALLOWED_HOSTS = []\n", LangPy)
+
+	assert.Equal(t,
+		Fingerprint("missing-hsts", "settings.py", before.Ast, before.Source),
+		Fingerprint("missing-hsts", "settings.py", after.Ast, after.Source),
+		"changes to the file should not change the fingerprint of an issue on the whole file")
+}
@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TextEdit replaces the source between StartByte and EndByte with NewText.
+// An edit with StartByte == EndByte inserts NewText.
+type TextEdit struct {
+	StartByte  uint32
+	EndByte    uint32
+	StartPoint sitter.Point
+	EndPoint   sitter.Point
+	NewText    string
+}
+
+// SuggestedFix is a change to the source that fixes an issue.
+// The edits must not overlap.
+type SuggestedFix struct {
+	// Description says what the fix does, like "Use hashlib.sha256"
+	Description string
+	Edits       []TextEdit
+}
+
+// ReplaceNode returns an edit that replaces the source of node with text.
+func ReplaceNode(node *sitter.Node, text string) TextEdit {
+	return TextEdit{
+		StartByte:  node.StartByte(),
+		EndByte:    node.EndByte(),
+		StartPoint: node.StartPoint(),
+		EndPoint:   node.EndPoint(),
+		NewText:    text,
+	}
+}
+
+// DeleteNode returns an edit that removes the source of node.
+func DeleteNode(node *sitter.Node) TextEdit {
+	return ReplaceNode(node, "")
+}
+
+// InsertBefore returns an edit that inserts text right before node.
+func InsertBefore(node *sitter.Node, text string) TextEdit {
+	return TextEdit{
+		StartByte:  node.StartByte(),
+		EndByte:    node.StartByte(),
+		StartPoint: node.StartPoint(),
+		EndPoint:   node.StartPoint(),
+		NewText:    text,
+	}
+}
+
+// InsertAfter returns an edit that inserts text right after node.
+func InsertAfter(node *sitter.Node, text string) TextEdit {
+	return TextEdit{
+		StartByte:  node.EndByte(),
+		EndByte:    node.EndByte(),
+		StartPoint: node.EndPoint(),
+		EndPoint:   node.EndPoint(),
+		NewText:    text,
+	}
+}
+
+var (
+	ErrEditOutOfRange = errors.New("edit is out of the range of the source")
+	ErrEditsOverlap   = errors.New("edits overlap")
+	ErrFixSyntax      = errors.New("fixed source has syntax errors")
+)
+
+// ApplyEdits returns source with edits applied. The edits can be in any order,
+// but must not overlap, except for insertions at the same offset, which are
+// applied in the order they are given.
+func ApplyEdits(source []byte, edits []TextEdit) ([]byte, error) {
+	sorted := slices.Clone(edits)
+	slices.SortStableFunc(sorted, func(a, b TextEdit) int {
+		if a.StartByte != b.StartByte {
+			return int(a.StartByte) - int(b.StartByte)
+		}
+		return int(a.EndByte) - int(b.EndByte)
+	})
+
+	var fixed []byte
+	offset := uint32(0)
+	for _, edit := range sorted {
+		if edit.StartByte > edit.EndByte || int(edit.EndByte) > len(source) {
+			return nil, ErrEditOutOfRange
+		}
+		if edit.StartByte < offset {
+			return nil, ErrEditsOverlap
+		}
+
+		fixed = append(fixed, source[offset:edit.StartByte]...)
+		fixed = append(fixed, edit.NewText...)
+		offset = edit.EndByte
+	}
+
+	return append(fixed, source[offset:]...), nil
+}
+
+// ValidateFix checks that the edits of fix don't overlap, and that the file
+// has no more syntax errors after they are applied than it had before.
+func ValidateFix(file *ParseResult, fix SuggestedFix) error {
+	// the fixed code is checked as a whole, in the file it is embedded in
+	if file.Host != nil {
+		return ValidateFix(file.Host, fix)
+	}
+
+	fixed, err := ApplyEdits(file.Source, fix.Edits)
+	if err != nil {
+		return err
+	}
+
+	if file.TsLanguage == nil || file.Ast == nil || file.Ast.HasError() {
+		return nil
+	}
+
+	ast, err := sitter.ParseCtx(context.Background(), fixed, file.TsLanguage)
+	if err != nil {
+		return fmt.Errorf("failed to parse the fixed source: %w", err)
+	}
+
+	if ast.HasError() {
+		return ErrFixSyntax
+	}
+
+	return nil
+}
+
+// FixesOverlap reports whether any edit of a overlaps with an edit of b, so that
+// the two fixes can't be applied together. Insertions at the same offset overlap.
+func FixesOverlap(a, b SuggestedFix) bool {
+	for _, editA := range a.Edits {
+		for _, editB := range b.Edits {
+			if editA.StartByte == editB.StartByte ||
+				(editA.StartByte < editB.EndByte && editB.StartByte < editA.EndByte) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEdits(t *testing.T) {
+	source := []byte("hashlib.md5(data)")
+
+	tests := []struct {
+		name    string
+		edits   []TextEdit
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "replacement",
+			edits: []TextEdit{{StartByte: 8, EndByte: 11, NewText: "sha256"}},
+			want:  "hashlib.sha256(data)",
+		},
+		{
+			name: "edits in any order",
+			edits: []TextEdit{
+				{StartByte: 12, EndByte: 16, NewText: "payload"},
+				{StartByte: 8, EndByte: 11, NewText: "sha256"},
+			},
+			want: "hashlib.sha256(payload)",
+		},
+		{
+			name: "insertions at the same offset",
+			edits: []TextEdit{
+				{StartByte: 0, EndByte: 0, NewText: "a = "},
+				{StartByte: 0, EndByte: 0, NewText: "b = "},
+			},
+			want: "a = b = hashlib.md5(data)",
+		},
+		{
+			name: "overlapping edits",
+			edits: []TextEdit{
+				{StartByte: 0, EndByte: 11, NewText: "sha256"},
+				{StartByte: 8, EndByte: 17, NewText: "x"},
+			},
+			wantErr: ErrEditsOverlap,
+		},
+		{
+			name:    "out of range",
+			edits:   []TextEdit{{StartByte: 12, EndByte: 40, NewText: "x"}},
+			wantErr: ErrEditOutOfRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyEdits(source, tt.edits)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestFixesOverlap(t *testing.T) {
+	a := SuggestedFix{Edits: []TextEdit{{StartByte: 0, EndByte: 5}}}
+	b := SuggestedFix{Edits: []TextEdit{{StartByte: 4, EndByte: 8}}}
+	c := SuggestedFix{Edits: []TextEdit{{StartByte: 5, EndByte: 8}}}
+	d := SuggestedFix{Edits: []TextEdit{{StartByte: 5, EndByte: 5}}}
+
+	assert.True(t, FixesOverlap(a, b))
+	assert.False(t, FixesOverlap(a, c))
+	assert.True(t, FixesOverlap(c, d))
+}
+
+// md5Checker reports calls to hashlib.md5 and suggests sha256, and a fix that breaks the syntax
+func md5Checker(pass *Pass) (any, error) {
+	Preorder(pass, func(node *sitter.Node) {
+		if node.Type() != "call" {
+			return
+		}
+
+		function := node.ChildByFieldName("function")
+		if function == nil || function.Content(pass.FileContext.Source) != "hashlib.md5" {
+			return
+		}
+
+		pass.ReportWithFixes(pass, node, "md5 is a weak hash",
+			SuggestedFix{
+				Description: "Use hashlib.sha256",
+				Edits:       []TextEdit{ReplaceNode(function, "hashlib.sha256")},
+			},
+			SuggestedFix{
+				Description: "Broken fix",
+				Edits:       []TextEdit{ReplaceNode(function, "hashlib.sha256((")},
+			},
+		)
+	})
+	return nil, nil
+}
+
+func TestReportWithFixes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.py")
+	require.NoError(t, os.WriteFile(path, []byte("import hashlib\n\nhashlib.md5(data)\n"), 0644))
+
+	analyzer := &Analyzer{
+		Name:     "weak-hash",
+		Language: LangPy,
+		Run:      md5Checker,
+	}
+
+	issues, err := RunAnalyzers(dir, []*Analyzer{analyzer}, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+
+	// the fix that leaves a syntax error is dropped
+	issue := issues[0]
+	require.Len(t, issue.Fixes, 1)
+	assert.Equal(t, "Use hashlib.sha256", issue.Fixes[0].Description)
+
+	source, err := os.ReadFile(path)
+	require.NoError(t, err)
+	fixed, err := ApplyEdits(source, issue.Fixes[0].Edits)
+	require.NoError(t, err)
+	assert.Equal(t, "import hashlib\n\nhashlib.sha256(data)\n", string(fixed))
+
+	t.Run("json", func(t *testing.T) {
+		out, err := issue.AsJson()
+		require.NoError(t, err)
+
+		decoded, err := IssueFromJson(out)
+		require.NoError(t, err)
+		assert.Equal(t, issue.Fixes, decoded.Fixes)
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		out, err := ReportIssues(issues, "sarif")
+		require.NoError(t, err)
+
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(out, &log))
+		require.Len(t, log.Runs, 1)
+		require.Len(t, log.Runs[0].Results, 1)
+
+		result := log.Runs[0].Results[0]
+		assert.Equal(t, "weak-hash", result.RuleId)
+		assert.Equal(t, 3, result.Locations[0].PhysicalLocation.Region.StartLine)
+		require.Len(t, result.Fixes, 1)
+
+		replacement := result.Fixes[0].ArtifactChanges[0].Replacements[0]
+		assert.Equal(t, uint32(16), *replacement.DeletedRegion.ByteOffset)
+		assert.Equal(t, uint32(11), *replacement.DeletedRegion.ByteLength)
+		assert.Equal(t, "hashlib.sha256", replacement.InsertedContent.Text)
+	})
+}
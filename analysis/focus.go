@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ReportRange is the part of the reported node that an issue of a YAML checker covers.
+type ReportRange string
+
+const (
+	// ReportRangeNode covers the whole node
+	ReportRangeNode ReportRange = "node"
+	// ReportRangeLine covers the first line of the node, without its indentation
+	ReportRangeLine ReportRange = "line"
+	// ReportRangeName covers the name of the node, like the name of a declaration
+	// or the function of a call, and the node when it has no name
+	ReportRangeName ReportRange = "name"
+)
+
+func (r ReportRange) IsValid() bool {
+	switch r {
+	case ReportRangeNode, ReportRangeLine, ReportRangeName:
+		return true
+	}
+	return false
+}
+
+// nameFields are the fields of a node that hold its name, in order of preference
+var nameFields = []string{"name", "key", "function", "property", "field", "attribute"}
+
+// compileFocus checks that every capture in focus is a capture of one of the patterns.
+func compileFocus(focus []string, patterns []*sitter.Query) error {
+	for _, capture := range focus {
+		if !slices.ContainsFunc(patterns, func(q *sitter.Query) bool { return queryHasCapture(q, capture) }) {
+			return fmt.Errorf("focus: @%s is not a capture in the pattern", capture)
+		}
+	}
+	return nil
+}
+
+// focusNode returns the node of the first capture in focus that is in the match,
+// or matched when there is none.
+func focusNode(focus []string, query *sitter.Query, captures []sitter.QueryCapture, matched *sitter.Node) *sitter.Node {
+	for _, name := range focus {
+		for _, capture := range captures {
+			if query.CaptureNameForId(capture.Index) == name {
+				return capture.Node
+			}
+		}
+	}
+	return matched
+}
+
+// nameNode returns the node that names node. For a call on a member, like
+// hashlib.md5(data), it is the name of the member.
+func nameNode(node *sitter.Node) *sitter.Node {
+	name := node
+	for {
+		var next *sitter.Node
+		for _, field := range nameFields {
+			if next = name.ChildByFieldName(field); next != nil {
+				break
+			}
+		}
+
+		if next == nil {
+			return name
+		}
+		name = next
+	}
+}
+
+// lineRange returns the range of the first line of node, without the whitespace around it.
+func lineRange(node *sitter.Node, source []byte) sitter.Range {
+	start := node.StartByte()
+	lineStart := uint32(bytes.LastIndexByte(source[:start], '\n') + 1)
+	for lineStart < start && (source[lineStart] == ' ' || source[lineStart] == '\t') {
+		lineStart++
+	}
+
+	end := uint32(len(source))
+	if i := bytes.IndexByte(source[start:], '\n'); i >= 0 {
+		end = start + uint32(i)
+	}
+	for end > start && (source[end-1] == ' ' || source[end-1] == '\t' || source[end-1] == '\r') {
+		end--
+	}
+
+	row := node.StartPoint().Row
+	return sitter.Range{
+		StartPoint: sitter.Point{Row: row, Column: node.StartPoint().Column - (start - lineStart)},
+		EndPoint:   sitter.Point{Row: row, Column: node.StartPoint().Column + (end - start)},
+		StartByte:  lineStart,
+		EndByte:    end,
+	}
+}
@@ -0,0 +1,208 @@
+package analysis
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Injection describes code of one language embedded in the nodes of another, like
+// SQL in the strings of a Python file. The embedded code is parsed with the grammar
+// of its language, and the checkers of that language run on it like on any other file.
+//
+// The embedded code is parsed in place in the source of the host file, so the nodes
+// of its tree have the positions of the code in the host file, and issues, fixes and
+// skipcq comments work without mapping positions.
+type Injection struct {
+	// Host is the language of the files the code is embedded in
+	Host Language
+	// NodeTypes are the types of the host nodes that hold the embedded code
+	NodeTypes []string
+	// Target is the language of the embedded code
+	Target Language
+	// (optional) Ranges returns the parts of a host node that hold the code, like
+	// the content of a string without its quotes. By default, it is the whole node.
+	Ranges func(node *sitter.Node, source []byte) []sitter.Range
+	// (optional) Detect reports whether content, the code in the ranges of a node,
+	// is code of the target language. By default, the content of every node is.
+	Detect func(content []byte) bool
+}
+
+// DefaultInjections are the languages that are embedded in other languages often
+// enough to be analyzed by default.
+var DefaultInjections = []Injection{
+	{
+		Host:      LangPy,
+		NodeTypes: []string{"string"},
+		Target:    LangSql,
+		Ranges:    pythonStringRanges,
+		Detect:    looksLikeSql,
+	},
+	{
+		Host:      LangGo,
+		NodeTypes: []string{"interpreted_string_literal", "raw_string_literal"},
+		Target:    LangSql,
+		Ranges:    unquotedRanges,
+		Detect:    looksLikeSql,
+	},
+	{
+		Host:      LangHtml,
+		NodeTypes: []string{"raw_text"},
+		Target:    LangJs,
+		Ranges:    scriptRanges,
+	},
+	{
+		Host:      LangDockerfile,
+		NodeTypes: []string{"shell_command"},
+		Target:    LangBash,
+	},
+}
+
+// sqlRegexp matches the start of the common SQL statements, with enough of the
+// statement that strings of prose starting with words like "update" don't match
+var sqlRegexp = regexp.MustCompile(`(?is)^\s*(select\s.*\bfrom\b|insert\s+into\b|update\s+\S+\s+set\b|delete\s+from\b|(create|drop|alter)\s+(table|index|view)\b)`)
+
+func looksLikeSql(content []byte) bool {
+	return sqlRegexp.Match(content)
+}
+
+// pythonStringRanges returns the content of a Python string without its quotes and
+// the interpolations of an f-string. Docstrings are prose, and are left out.
+func pythonStringRanges(node *sitter.Node, source []byte) []sitter.Range {
+	if parent := node.Parent(); parent != nil && parent.Type() == "expression_statement" {
+		return nil
+	}
+
+	var ranges []sitter.Range
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "string_content" {
+			ranges = append(ranges, child.Range())
+		}
+	}
+	return ranges
+}
+
+// unquotedRanges returns a string literal without its quotes, which are a single byte.
+func unquotedRanges(node *sitter.Node, source []byte) []sitter.Range {
+	rng := node.Range()
+	if rng.EndByte-rng.StartByte < 2 {
+		return nil
+	}
+
+	rng.StartByte++
+	rng.StartPoint.Column++
+	rng.EndByte--
+	rng.EndPoint.Column--
+	return []sitter.Range{rng}
+}
+
+// scriptRanges returns the text of a <script> element, unless its type isn't JavaScript.
+func scriptRanges(node *sitter.Node, source []byte) []sitter.Range {
+	script := node.Parent()
+	if script == nil || script.Type() != "script_element" {
+		return nil
+	}
+
+	if scriptType := htmlAttribute(script, "type", source); scriptType != "" {
+		scriptType = strings.ToLower(scriptType)
+		if !strings.Contains(scriptType, "javascript") && scriptType != "module" {
+			return nil
+		}
+	}
+
+	return []sitter.Range{node.Range()}
+}
+
+// htmlAttribute returns the value of the attribute name in the start tag of element.
+func htmlAttribute(element *sitter.Node, name string, source []byte) string {
+	tag := element.NamedChild(0)
+	if tag == nil || tag.Type() != "start_tag" {
+		return ""
+	}
+
+	for i := 0; i < int(tag.NamedChildCount()); i++ {
+		attribute := tag.NamedChild(i)
+		if attribute.Type() != "attribute" || attribute.NamedChildCount() < 2 {
+			continue
+		}
+
+		if strings.EqualFold(attribute.NamedChild(0).Content(source), name) {
+			return strings.Trim(attribute.NamedChild(1).Content(source), `"'`)
+		}
+	}
+	return ""
+}
+
+// InjectedFiles returns the code embedded in file by the injections with file's
+// language as host, as a file for each node that holds code. The injected files have
+// the path and source of file, which is their Host.
+func InjectedFiles(file *ParseResult, injections []Injection) []*ParseResult {
+	var hosted []Injection
+	for _, injection := range injections {
+		if injection.Host == file.Language && injection.Target.Grammar() != nil {
+			hosted = append(hosted, injection)
+		}
+	}
+	if len(hosted) == 0 || file.Ast == nil {
+		return nil
+	}
+
+	var injected []*ParseResult
+	walkTree(file.Ast, func(node *sitter.Node) {
+		for _, injection := range hosted {
+			if !slices.Contains(injection.NodeTypes, node.Type()) {
+				continue
+			}
+
+			if f := injectFile(file, injection, node); f != nil {
+				injected = append(injected, f)
+			}
+		}
+	})
+
+	return injected
+}
+
+// injectFile parses the code that injection finds in node, or returns nil when there is none.
+func injectFile(host *ParseResult, injection Injection, node *sitter.Node) *ParseResult {
+	ranges := []sitter.Range{node.Range()}
+	if injection.Ranges != nil {
+		ranges = injection.Ranges(node, host.Source)
+	}
+
+	var content []byte
+	for _, rng := range ranges {
+		content = append(content, host.Source[rng.StartByte:rng.EndByte]...)
+	}
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return nil
+	}
+
+	if injection.Detect != nil && !injection.Detect(content) {
+		return nil
+	}
+
+	grammar := injection.Target.Grammar()
+	parser := sitter.NewParser()
+	parser.SetLanguage(grammar)
+	parser.SetIncludedRanges(ranges)
+
+	tree, err := parser.ParseCtx(context.Background(), nil, host.Source)
+	if err != nil {
+		return nil
+	}
+
+	ast := tree.RootNode()
+	return &ParseResult{
+		Ast:        ast,
+		Source:     host.Source,
+		FilePath:   host.FilePath,
+		TsLanguage: grammar,
+		Language:   injection.Target,
+		ScopeTree:  MakeScopeTree(injection.Target, ast, host.Source),
+		Host:       host,
+	}
+}
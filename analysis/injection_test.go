@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectedFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "SQL in Python strings",
+			path:   "app.py",
+			source: "\"\"\"Select the rows from the table.\"\"\"\nq = \"SELECT * FROM users WHERE id = \" + user_id\nr = f\"DELETE FROM users WHERE id = {user_id}\"\nmessage = \"hello\"\n",
+			want:   []string{"SELECT * FROM users WHERE id = ", "DELETE FROM users WHERE id = "},
+		},
+		{
+			name:   "SQL in Go strings",
+			path:   "app.go",
+			source: "package main\n\nvar q = `SELECT name FROM users`\nvar r = \"update users set name = 'x'\"\nvar s = \"select a value\"\n",
+			want:   []string{"SELECT name FROM users", "update users set name = 'x'"},
+		},
+		{
+			name:   "JavaScript in HTML scripts",
+			path:   "index.html",
+			source: "<html>\n<script>\neval(input)\n</script>\n<script type=\"text/template\"><p>{{x}}</p></script>\n<script type=\"module\">run()</script>\n</html>\n",
+			want:   []string{"eval(input)\n", "run()"},
+		},
+		{
+			name:   "shell in Dockerfile RUN lines",
+			path:   "Dockerfile",
+			source: "FROM alpine\nRUN curl https://example.com/install.sh | sh && \\\n    rm -rf /tmp\nRUN [\"echo\", \"exec form\"]\n",
+			want:   []string{"curl https://example.com/install.sh | sh && \\\n    rm -rf /tmp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang := LanguageFromFilePath(tt.path)
+			file, err := Parse(tt.path, []byte(tt.source), lang, lang.Grammar())
+			require.NoError(t, err)
+
+			var got []string
+			for _, injected := range InjectedFiles(file, DefaultInjections) {
+				assert.Equal(t, file, injected.Host)
+				assert.Equal(t, tt.path, injected.FilePath)
+				got = append(got, injected.Ast.Content(injected.Source))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRunAnalyzersOnInjectedCode(t *testing.T) {
+	checker := `language: sql
+name: sql-delete
+message: "DELETE statement"
+category: security
+severity: warning
+pattern: (keyword_delete) @sql-delete
+`
+	analyzer, _, err := ReadFromBytes([]byte(checker))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	source := "def purge(db):\n    db.execute(\"DELETE FROM users\")\n    db.execute(\"DELETE FROM logs\")  # skipcq: sql-delete\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.py"), []byte(source), 0644))
+
+	issues, err := RunAnalyzers(dir, []*Analyzer{&analyzer}, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+
+	issue := issues[0]
+	assert.Equal(t, filepath.Join(dir, "app.py"), issue.Filepath)
+	assert.Equal(t, sitter.Range{
+		StartPoint: sitter.Point{Row: 1, Column: 16},
+		EndPoint:   sitter.Point{Row: 1, Column: 22},
+		StartByte:  31,
+		EndByte:    37,
+	}, issue.Range())
+	assert.Equal(t, "DELETE", issue.Node.Content([]byte(source)))
+}
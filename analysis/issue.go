@@ -21,6 +21,31 @@ type Issue struct {
 	// Id is a unique ID for the issue.
 	// Issue that have 'Id's can be explained using the `globstar desc` command.
 	Id *string
+	// Fingerprint identifies the issue across runs, even when the code around
+	// it moves. See `Fingerprint` for how it is computed.
+	Fingerprint string
+	// (optional) Fixes are the changes suggested to fix the issue
+	Fixes []SuggestedFix
+	// (optional) Metadata is the metadata of the checker that raised the issue
+	Metadata Metadata
+
+	// the range of the issue when it isn't the range of Node: the range decoded
+	// by IssueFromJson, or the part of Node given to Pass.ReportRange
+	rng *sitter.Range
+}
+
+// Range returns the source range of the issue. Issues decoded by IssueFromJson
+// don't have a Node, and return the range they were decoded with.
+func (i *Issue) Range() sitter.Range {
+	if i.rng != nil {
+		return *i.rng
+	}
+
+	if i.Node != nil {
+		return i.Node.Range()
+	}
+
+	return sitter.Range{}
 }
 
 type location struct {
@@ -34,12 +59,64 @@ type position struct {
 	End      location `json:"end"`
 }
 
+type editJson struct {
+	Start     location `json:"start"`
+	End       location `json:"end"`
+	StartByte uint32   `json:"startByte"`
+	EndByte   uint32   `json:"endByte"`
+	NewText   string   `json:"newText"`
+}
+
+type fixJson struct {
+	Description string     `json:"description"`
+	Edits       []editJson `json:"edits"`
+}
+
 type issueJson struct {
-	Category Category `json:"category"`
-	Severity Severity `json:"severity"`
-	Message  string   `json:"message"`
-	Range    position `json:"range"`
-	Id       string   `json:"id"`
+	Category    Category  `json:"category"`
+	Severity    Severity  `json:"severity"`
+	Message     string    `json:"message"`
+	Range       position  `json:"range"`
+	Id          string    `json:"id"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Fixes       []fixJson `json:"fixes,omitempty"`
+	Metadata    *Metadata `json:"metadata,omitempty"`
+}
+
+func fixesToJson(fixes []SuggestedFix) []fixJson {
+	var out []fixJson
+	for _, fix := range fixes {
+		f := fixJson{Description: fix.Description, Edits: []editJson{}}
+		for _, edit := range fix.Edits {
+			f.Edits = append(f.Edits, editJson{
+				Start:     location{Row: int(edit.StartPoint.Row) + 1, Column: int(edit.StartPoint.Column)},
+				End:       location{Row: int(edit.EndPoint.Row) + 1, Column: int(edit.EndPoint.Column)},
+				StartByte: edit.StartByte,
+				EndByte:   edit.EndByte,
+				NewText:   edit.NewText,
+			})
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func fixesFromJson(fixes []fixJson) []SuggestedFix {
+	var out []SuggestedFix
+	for _, fix := range fixes {
+		f := SuggestedFix{Description: fix.Description}
+		for _, edit := range fix.Edits {
+			f.Edits = append(f.Edits, TextEdit{
+				StartByte:  edit.StartByte,
+				EndByte:    edit.EndByte,
+				StartPoint: sitter.Point{Row: uint32(max(edit.Start.Row-1, 0)), Column: uint32(edit.Start.Column)},
+				EndPoint:   sitter.Point{Row: uint32(max(edit.End.Row-1, 0)), Column: uint32(edit.End.Column)},
+				NewText:    edit.NewText,
+			})
+		}
+		out = append(out, f)
+	}
+	return out
 }
 
 func (i *Issue) AsJson() ([]byte, error) {
@@ -50,22 +127,28 @@ func (i *Issue) AsJson() ([]byte, error) {
 		Range: position{
 			Filename: i.Filepath,
 			Start: location{
-				Row:    int(i.Node.Range().StartPoint.Row) + 1, // 0-indexed to 1-indexed
-				Column: int(i.Node.Range().StartPoint.Column),
+				Row:    int(i.Range().StartPoint.Row) + 1, // 0-indexed to 1-indexed
+				Column: int(i.Range().StartPoint.Column),
 			},
 			End: location{
-				Row:    int(i.Node.Range().EndPoint.Row) + 1, // 0-indexed to 1-indexed
-				Column: int(i.Node.Range().EndPoint.Column),
+				Row:    int(i.Range().EndPoint.Row) + 1, // 0-indexed to 1-indexed
+				Column: int(i.Range().EndPoint.Column),
 			},
 		},
-		Id: *i.Id,
+		Id:          *i.Id,
+		Fingerprint: i.Fingerprint,
+		Fixes:       fixesToJson(i.Fixes),
+	}
+
+	if !i.Metadata.IsEmpty() {
+		issue.Metadata = &i.Metadata
 	}
 
 	return json.Marshal(issue)
 }
 
 func (i *Issue) AsText() ([]byte, error) {
-	return []byte(fmt.Sprintf("%s:%d:%d:%s", i.Filepath, int(i.Node.Range().StartPoint.Row)+1, i.Node.Range().StartPoint.Column, i.Message)), nil
+	return []byte(fmt.Sprintf("%s:%d:%d:%s", i.Filepath, int(i.Range().StartPoint.Row)+1, i.Range().StartPoint.Column, i.Message)), nil
 }
 
 func IssueFromJson(jsonData []byte) (*Issue, error) {
@@ -75,13 +158,34 @@ func IssueFromJson(jsonData []byte) (*Issue, error) {
 		return nil, err
 	}
 
+	decodedRange := &sitter.Range{
+		StartPoint: sitter.Point{
+			Row:    uint32(max(issue.Range.Start.Row-1, 0)), // 1-indexed to 0-indexed
+			Column: uint32(issue.Range.Start.Column),
+		},
+		EndPoint: sitter.Point{
+			Row:    uint32(max(issue.Range.End.Row-1, 0)), // 1-indexed to 0-indexed
+			Column: uint32(issue.Range.End.Column),
+		},
+	}
+
+	var metadata Metadata
+	if issue.Metadata != nil {
+		metadata = *issue.Metadata
+	}
+
 	return &Issue{
-		Category: issue.Category,
-		Severity: issue.Severity,
-		Message:  issue.Message,
-		Filepath: issue.Range.Filename,
-		Node:     nil,
-		Id:       &issue.Id,
+		Category:    issue.Category,
+		Severity:    issue.Severity,
+		Message:     issue.Message,
+		Filepath:    issue.Range.Filename,
+		Node:        nil,
+		Id:          &issue.Id,
+		Fingerprint: issue.Fingerprint,
+		Fixes:       fixesFromJson(issue.Fixes),
+		Metadata:    metadata,
+
+		rng: decodedRange,
 	}, nil
 }
 
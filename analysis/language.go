@@ -50,6 +50,12 @@ type ParseResult struct {
 	// ScopeTree represents the scope hierarchy of the file.
 	// Can be nil if scope support for this language has not been implemented yet.
 	ScopeTree *ScopeTree
+	// (optional) Host is the file that the code is embedded in, for the code of
+	// another language found by an Injection, like SQL in a Python string
+	Host *ParseResult
+
+	// skipComments caches the skipcq comments in the file, see SkipComments
+	skipComments []*SkipComment
 }
 
 type Language int
@@ -144,6 +150,67 @@ func DecodeLanguage(language string) Language {
 	}
 }
 
+// String returns the canonical name of the language, as accepted
+// by the `language` field of a YAML checker.
+func (lang Language) String() string {
+	switch lang {
+	case LangPy:
+		return "python"
+	case LangJs:
+		return "javascript"
+	case LangTs:
+		return "typescript"
+	case LangTsx:
+		return "tsx"
+	case LangJava:
+		return "java"
+	case LangRuby:
+		return "ruby"
+	case LangRust:
+		return "rust"
+	case LangYaml:
+		return "yaml"
+	case LangCss:
+		return "css"
+	case LangDockerfile:
+		return "dockerfile"
+	case LangMarkdown:
+		return "markdown"
+	case LangSql:
+		return "sql"
+	case LangKotlin:
+		return "kotlin"
+	case LangOCaml:
+		return "ocaml"
+	case LangLua:
+		return "lua"
+	case LangBash:
+		return "bash"
+	case LangCsharp:
+		return "csharp"
+	case LangElixir:
+		return "elixir"
+	case LangElm:
+		return "elm"
+	case LangGo:
+		return "go"
+	case LangGroovy:
+		return "groovy"
+	case LangHcl:
+		return "hcl"
+	case LangHtml:
+		return "html"
+	case LangPhp:
+		return "php"
+	case LangScala:
+		return "scala"
+	case LangSwift:
+		return "swift"
+	default:
+		return "unknown"
+	}
+}
+
 // tsGrammarForLang returns the tree-sitter grammar for the given language.
 // May return `nil` when `lang` is `LangUnkown`.
 func (lang Language) Grammar() *sitter.Language {
@@ -209,6 +276,11 @@ func (lang Language) Grammar() *sitter.Language {
 // LanguageFromFilePath returns the Language of the file at the given path
 // returns `LangUnkown` if the language is not recognized (e.g: `.txt` files).
 func LanguageFromFilePath(path string) Language {
+	// Dockerfiles are named by convention rather than by extension
+	if base := filepath.Base(path); base == "Dockerfile" || strings.HasPrefix(base, "Dockerfile.") {
+		return LangDockerfile
+	}
+
 	ext := filepath.Ext(path)
 	switch ext {
 	case ".py":
@@ -307,6 +379,10 @@ func ParseFile(filePath string) (*ParseResult, error) {
 	return Parse(filePath, source, lang, grammar)
 }
 
+// GetEscapedCommentIdentifierFromPath returns the regexp-escaped line comment prefix
+// of the language of the file at path.
+//
+// Deprecated: use Language.CommentSyntax, which also has block comments.
 func GetEscapedCommentIdentifierFromPath(path string) string {
 	lang := LanguageFromFilePath(path)
 	switch lang {
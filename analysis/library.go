@@ -0,0 +1,385 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"gopkg.in/yaml.v3"
+)
+
+// LibraryDir is the directory of a checker directory with the pattern fragments
+// that its checkers can use. The files in it are not checkers.
+const LibraryDir = "lib"
+
+// libraryYaml is a file of pattern fragments.
+type libraryYaml struct {
+	Fragments []*fragmentYaml `yaml:"fragments"`
+}
+
+// fragmentYaml is a named pattern, or list of patterns, that checkers use with
+// `use: <name>` instead of repeating it. "$param" in the patterns is replaced with
+// the value of the parameter, given in the `with` of the reference or its default.
+type fragmentYaml struct {
+	Name        string `yaml:"name"`
+	Language    string `yaml:"language"`
+	Description string `yaml:"description,omitempty"`
+	// Params are the parameters of the fragment with their default value,
+	// a parameter without one must be given
+	Params map[string]*string `yaml:"params,omitempty"`
+	// Pattern is a pattern, or a list of alternative patterns, where an item can
+	// be a reference to another fragment
+	Pattern yaml.Node `yaml:"pattern"`
+}
+
+// useYaml is a reference to a fragment, which is replaced with the patterns of
+// the fragment when a checker is read.
+type useYaml struct {
+	Use  string            `yaml:"use"`
+	With map[string]string `yaml:"with,omitempty"`
+	// As is the name of a capture added to each pattern of the fragment
+	As string `yaml:"as,omitempty"`
+}
+
+type fragment struct {
+	*fragmentYaml
+	lang Language
+	// path is the file the fragment is defined in
+	path string
+}
+
+// Library is a set of named pattern fragments that YAML checkers reference with `use:`.
+// The references are resolved when a checker is read, so a checker using fragments is
+// read with the methods of the library it uses.
+type Library struct {
+	fragments map[string]*fragment
+}
+
+// fragmentParamRegexp matches a parameter in the patterns of a fragment, or a name
+// escaped with `$$`.
+var fragmentParamRegexp = regexp.MustCompile(`\$\$?([A-Za-z_][A-Za-z0-9_]*)`)
+
+// alternativeKeys are the keys of the lists where a fragment with several patterns can
+// be used, since any one of the patterns in them can match.
+var alternativeKeys = []string{"patterns", "any", "sources", "sinks", "sanitizers", "propagators"}
+
+// LoadLibrary reads the fragments in the YAML files in dir, and its subdirectories.
+// The library is empty when dir doesn't exist.
+func LoadLibrary(fsys fs.FS, dir string) (*Library, error) {
+	lib := &Library{fragments: make(map[string]*fragment)}
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		if ext := path.Ext(p); ext != ".yml" && ext != ".yaml" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return lib.addFile(p, content)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lib, nil
+}
+
+// addFile adds the fragments defined in the library file at filePath.
+func (lib *Library) addFile(filePath string, content []byte) error {
+	var file libraryYaml
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("invalid fragment file %s: %w", filePath, err)
+	}
+
+	for _, f := range file.Fragments {
+		if f.Name == "" {
+			return fmt.Errorf("fragment without a name in %s", filePath)
+		}
+
+		if other, ok := lib.fragments[f.Name]; ok {
+			return fmt.Errorf("fragment '%s' is defined in both %s and %s", f.Name, other.path, filePath)
+		}
+
+		lang := DecodeLanguage(f.Language)
+		if lang == LangUnknown {
+			return fmt.Errorf("fragment '%s' (%s) has an unknown language '%s'", f.Name, filePath, f.Language)
+		}
+
+		if f.Pattern.Kind == 0 {
+			return fmt.Errorf("fragment '%s' (%s) has no pattern", f.Name, filePath)
+		}
+
+		lib.fragments[f.Name] = &fragment{fragmentYaml: f, lang: lang, path: filePath}
+	}
+
+	return nil
+}
+
+// ReadFromBytes reads a single checker, like the package's ReadFromBytes, with its
+// fragment references resolved in the library.
+func (lib *Library) ReadFromBytes(fileContent []byte) (Analyzer, YamlAnalyzer, error) {
+	return readFromBytes(fileContent, lib)
+}
+
+// ReadAllFromBytes reads all the checkers in a file, like the package's ReadAllFromBytes,
+// with their fragment references resolved in the library.
+func (lib *Library) ReadAllFromBytes(fileContent []byte) ([]Analyzer, error) {
+	return readAllFromBytes(fileContent, lib)
+}
+
+// DecodeYamlCheckers decodes the checker definitions in a file, like the package's
+// DecodeYamlCheckers, with their fragment references resolved in the library.
+func (lib *Library) DecodeYamlCheckers(fileContent []byte) ([]Yaml, error) {
+	return decodeYamlCheckers(fileContent, lib)
+}
+
+// resolve replaces the fragment references in a checker document with the patterns of
+// the fragments. A reference in a list of alternatives is replaced with all the patterns
+// of the fragment, anywhere else the fragment must have a single pattern.
+func (lib *Library) resolve(document *yaml.Node) error {
+	var languages []Language
+	if checker := document.Content[0]; checker.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(checker.Content); i += 2 {
+			key, val := checker.Content[i], checker.Content[i+1]
+			switch key.Value {
+			case "language":
+				languages = append(languages, DecodeLanguage(val.Value))
+			case "languages":
+				for _, name := range val.Content {
+					languages = append(languages, DecodeLanguage(name.Value))
+				}
+			}
+		}
+	}
+
+	return lib.resolveNode(document, "", languages)
+}
+
+func (lib *Library) resolveNode(node *yaml.Node, key string, languages []Language) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := lib.resolveNode(child, key, languages); err != nil {
+				return err
+			}
+		}
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			ref, err := decodeUse(v)
+			if err != nil {
+				return err
+			}
+
+			if ref == nil {
+				if err := lib.resolveNode(v, k.Value, languages); err != nil {
+					return err
+				}
+				continue
+			}
+
+			patterns, err := lib.expand(ref, languages, nil)
+			if err != nil {
+				return err
+			}
+			if len(patterns) != 1 {
+				return fmt.Errorf("fragment '%s' (%s) has %d patterns, and can't be used in '%s', only in a list like 'patterns' or 'sinks'", ref.Use, lib.fragments[ref.Use].path, len(patterns), k.Value)
+			}
+			*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: patterns[0]}
+		}
+
+	case yaml.SequenceNode:
+		var content []*yaml.Node
+		for _, item := range node.Content {
+			ref, err := decodeUse(item)
+			if err != nil {
+				return err
+			}
+
+			if ref == nil {
+				if err := lib.resolveNode(item, key, languages); err != nil {
+					return err
+				}
+				content = append(content, item)
+				continue
+			}
+
+			patterns, err := lib.expand(ref, languages, nil)
+			if err != nil {
+				return err
+			}
+			if len(patterns) > 1 && !slices.Contains(alternativeKeys, key) {
+				return fmt.Errorf("fragment '%s' (%s) has %d patterns, and can't be used in '%s', only in a list like 'patterns' or 'sinks'", ref.Use, lib.fragments[ref.Use].path, len(patterns), key)
+			}
+			for _, pattern := range patterns {
+				content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: pattern})
+			}
+		}
+		node.Content = content
+	}
+
+	return nil
+}
+
+// decodeUse returns the fragment reference in node, or nil when node isn't one.
+func decodeUse(node *yaml.Node) (*useYaml, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	isUse := false
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == "use" {
+			isUse = true
+		}
+	}
+	if !isUse {
+		return nil, nil
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key != "use" && key != "with" && key != "as" {
+			return nil, fmt.Errorf("line %d: unknown key '%s' in the reference to a fragment, expected 'use', 'with' or 'as'", node.Content[i].Line, key)
+		}
+	}
+
+	var ref useYaml
+	if err := node.Decode(&ref); err != nil {
+		return nil, err
+	}
+	if ref.Use == "" {
+		return nil, fmt.Errorf("line %d: 'use' needs the name of a fragment", node.Line)
+	}
+	return &ref, nil
+}
+
+// expand returns the patterns of the fragment ref refers to, with its parameters replaced.
+// The fragment must be in one of languages. used are the fragments being expanded, that
+// ref refers to through other fragments, and it can't be one of them.
+func (lib *Library) expand(ref *useYaml, languages []Language, used []string) ([]string, error) {
+	var f *fragment
+	if lib != nil {
+		f = lib.fragments[ref.Use]
+	}
+	if f == nil {
+		return nil, fmt.Errorf("unknown fragment '%s', fragments are defined in the files of the '%s' directory", ref.Use, LibraryDir)
+	}
+
+	if slices.Contains(used, f.Name) {
+		return nil, fmt.Errorf("fragment '%s' (%s) uses itself: %s", f.Name, f.path, strings.Join(append(used, f.Name), " -> "))
+	}
+	used = append(used, f.Name)
+
+	if len(languages) > 0 && !slices.Contains(languages, f.lang) {
+		return nil, fmt.Errorf("fragment '%s' (%s) is for %s, which is not a language of the checker using it", f.Name, f.path, f.Language)
+	}
+
+	args := make(map[string]string, len(f.Params))
+	for name := range ref.With {
+		if _, ok := f.Params[name]; !ok {
+			return nil, fmt.Errorf("fragment '%s' (%s) has no parameter '%s'", f.Name, f.path, name)
+		}
+	}
+	for name, def := range f.Params {
+		if value, ok := ref.With[name]; ok {
+			args[name] = value
+		} else if def != nil {
+			args[name] = *def
+		} else {
+			return nil, fmt.Errorf("fragment '%s' (%s) needs a value for the parameter '%s'", f.Name, f.path, name)
+		}
+	}
+
+	items := []*yaml.Node{&f.Pattern}
+	if f.Pattern.Kind == yaml.SequenceNode {
+		items = f.Pattern.Content
+	}
+
+	var patterns []string
+	substituted := make(map[string]bool, len(args))
+	for _, item := range items {
+		nested, err := decodeUse(item)
+		if err != nil {
+			return nil, fmt.Errorf("fragment '%s' (%s): %w", f.Name, f.path, err)
+		}
+
+		if nested != nil {
+			with := make(map[string]string, len(nested.With))
+			for name, value := range nested.With {
+				with[name] = f.substitute(value, args, substituted)
+			}
+			nested.With = with
+
+			expanded, err := lib.expand(nested, []Language{f.lang}, used)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, expanded...)
+			continue
+		}
+
+		if item.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("fragment '%s' (%s): a pattern must be a string or a 'use' reference", f.Name, f.path)
+		}
+
+		pattern := f.substitute(item.Value, args, substituted)
+		query, err := sitter.NewQuery([]byte(pattern), f.lang.Grammar())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in fragment '%s' (%s): %w", f.Name, f.path, err)
+		}
+		query.Close()
+
+		patterns = append(patterns, pattern)
+	}
+
+	// a parameter that isn't used is most likely misspelled in the patterns
+	for name := range args {
+		if !substituted[name] {
+			return nil, fmt.Errorf("fragment '%s' (%s) doesn't use its parameter '%s'", f.Name, f.path, name)
+		}
+	}
+
+	if ref.As != "" {
+		for i := range patterns {
+			// the pattern is grouped, so that the capture applies to all of it
+			patterns[i] = "(" + patterns[i] + ") @" + ref.As
+		}
+	}
+
+	return patterns, nil
+}
+
+// substitute replaces the parameters of the fragment in text with their value in args,
+// and records them in substituted. Other names are left as they are, and `$$name` is
+// replaced with `$name`.
+func (f *fragment) substitute(text string, args map[string]string, substituted map[string]bool) string {
+	return fragmentParamRegexp.ReplaceAllStringFunc(text, func(param string) string {
+		if strings.HasPrefix(param, "$$") {
+			return param[1:]
+		}
+		value, ok := args[param[1:]]
+		if !ok {
+			return param
+		}
+		substituted[param[1:]] = true
+		return value
+	})
+}
@@ -0,0 +1,175 @@
+package analysis
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunYamlTestsWithLibrary(t *testing.T) {
+	testDir := "testdata/yaml_tests/library"
+	tests, err := FindYamlTestFiles(testDir)
+	require.NoError(t, err)
+	require.Len(t, tests, 1, "the fragment files are not checkers")
+	assert.Equal(t, "testdata/yaml_tests/library/no-direct-http.yml", tests[0].YamlCheckerPath)
+
+	passed, err := RunYamlTests(testDir)
+	assert.NoError(t, err)
+	assert.True(t, passed)
+}
+
+func TestLibraryResolve(t *testing.T) {
+	lib, err := LoadLibrary(os.DirFS("testdata/yaml_tests/library"), LibraryDir)
+	require.NoError(t, err)
+
+	checker := `language: py
+name: requests-call
+message: "request"
+match:
+  all:
+    - use: client-call
+      as: requests-call
+    - not:
+        use: urlopen-call
+`
+	definitions, err := lib.DecodeYamlCheckers([]byte(checker))
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+
+	all := definitions[0].Match.All
+	require.Len(t, all, 2)
+	assert.Contains(t, all[0].Pattern, `(#eq? @requests-module "requests")`)
+	assert.Contains(t, all[0].Pattern, `"^(get|post|put|delete|request)$"`)
+	assert.Contains(t, all[0].Pattern, ") @requests-call")
+	assert.Contains(t, all[1].Not.Pattern, `(#eq? @urlopen "urlopen")`)
+
+	_, _, err = ReadFromBytes([]byte(checker))
+	assert.ErrorContains(t, err, "unknown fragment 'client-call'")
+}
+
+func TestLibraryErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/fragments.yml": {Data: []byte(`fragments:
+  - name: call
+    language: py
+    params:
+      fn:
+    pattern: '(call function: (identifier) @fn (#eq? @fn "$fn"))'
+  - name: calls
+    language: py
+    pattern:
+      - (call)
+      - (await)
+  - name: loop-a
+    language: py
+    pattern:
+      - use: loop-b
+  - name: loop-b
+    language: py
+    pattern:
+      - use: loop-a
+  - name: go-call
+    language: go
+    pattern: (call_expression)
+  - name: invalid
+    language: py
+    pattern: '(call function (identifier))'
+  - name: typo
+    language: py
+    params:
+      fn: eval
+    pattern: '(call function: (identifier) @fn (#eq? @fn "$fun"))'
+`)},
+	}
+
+	lib, err := LoadLibrary(fsys, LibraryDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{"unknown fragment", "pattern:\n  use: nope\n", "unknown fragment 'nope'"},
+		{"missing parameter", "pattern:\n  use: call\n", "fragment 'call' (lib/fragments.yml) needs a value for the parameter 'fn'"},
+		{"unknown parameter", "pattern:\n  use: call\n  with: {fn: eval, name: x}\n", "fragment 'call' (lib/fragments.yml) has no parameter 'name'"},
+		{"unused parameter", "pattern:\n  use: typo\n", "fragment 'typo' (lib/fragments.yml) doesn't use its parameter 'fn'"},
+		{"several patterns in a pattern", "pattern:\n  use: calls\n", "fragment 'calls' (lib/fragments.yml) has 2 patterns"},
+		{"several patterns in all", "match:\n  all:\n    - use: calls\n", "can't be used in 'all'"},
+		{"cycle", "patterns:\n  - use: loop-a\n", "fragment 'loop-a' (lib/fragments.yml) uses itself: loop-a -> loop-b -> loop-a"},
+		{"other language", "pattern:\n  use: go-call\n", "fragment 'go-call' (lib/fragments.yml) is for go"},
+		{"invalid pattern", "pattern:\n  use: invalid\n", "invalid pattern in fragment 'invalid' (lib/fragments.yml)"},
+		{"unknown key", "pattern:\n  use: calls\n  capture: x\n", "unknown key 'capture'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := lib.ReadAllFromBytes([]byte("language: py\nname: test\nmessage: test\n" + tt.pattern))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	t.Run("several patterns in patterns", func(t *testing.T) {
+		checkers, err := lib.ReadAllFromBytes([]byte("language: py\nname: test\nmessage: test\npatterns:\n  - use: calls\n    as: test\n"))
+		require.NoError(t, err)
+		assert.Len(t, checkers, 1)
+	})
+}
+
+func TestLibraryLiteralDollar(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/fragments.yml": {Data: []byte(`fragments:
+  - name: env-var
+    language: py
+    params:
+      name: HOME
+    pattern: '(string) @s (#match? @s "^\"\\$(IFS|$name)\"$") (#not-eq? @s "\"$$name\"")'
+  - name: assignment
+    language: py
+    pattern: '(identifier) @left "=" (string)'
+`)},
+	}
+
+	lib, err := LoadLibrary(fsys, LibraryDir)
+	require.NoError(t, err)
+
+	definitions, err := lib.DecodeYamlCheckers([]byte("language: py\nname: env\nmessage: env\npattern:\n  use: env-var\n  as: env\n"))
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, `((string) @s (#match? @s "^\"\\$(IFS|HOME)\"$") (#not-eq? @s "\"$name\"")) @env`, definitions[0].Pattern)
+
+	ana, _, err := lib.ReadFromBytes([]byte("language: py\nname: env\nmessage: env\npattern:\n  use: env-var\n  as: env\n"))
+	require.NoError(t, err)
+	parsed := parseTestFile(t, "a.py", "a = \"$HOME\"\nb = \"$IFS\"\nc = \"$PATH\"\n", LangPy)
+	issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{&ana})
+	require.NoError(t, err)
+	var rows []int
+	for _, issue := range issues {
+		rows = append(rows, int(issue.Range().StartPoint.Row)+1)
+	}
+	assert.Equal(t, []int{1, 2}, rows)
+
+	// the capture of `as` applies to all the nodes of the fragment, not only to its last one
+	definitions, err = lib.DecodeYamlCheckers([]byte("language: py\nname: assign\nmessage: assign\npattern:\n  use: assignment\n  as: assign\n"))
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, `((identifier) @left "=" (string)) @assign`, definitions[0].Pattern)
+}
+
+func TestLoadLibraryDuplicate(t *testing.T) {
+	fragment := []byte("fragments:\n  - name: call\n    language: py\n    pattern: (call)\n")
+	fsys := fstest.MapFS{
+		"lib/a.yml": {Data: fragment},
+		"lib/b.yml": {Data: fragment},
+	}
+
+	_, err := LoadLibrary(fsys, LibraryDir)
+	assert.EqualError(t, err, "fragment 'call' is defined in both lib/a.yml and lib/b.yml")
+
+	lib, err := LoadLibrary(fstest.MapFS{}, LibraryDir)
+	require.NoError(t, err)
+	assert.Empty(t, lib.fragments)
+}
@@ -0,0 +1,369 @@
+package analysis
+
+import (
+	"fmt"
+	"sync"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"gopkg.in/yaml.v3"
+)
+
+// matchYaml is a node of the `match` tree of a YAML checker. A string is
+// shorthand for a `pattern`, and exactly one operator can be set.
+type matchYaml struct {
+	Pattern   string       `yaml:"pattern,omitempty"`
+	All       []*matchYaml `yaml:"all,omitempty"`
+	Any       []*matchYaml `yaml:"any,omitempty"`
+	Not       *matchYaml   `yaml:"not,omitempty"`
+	Inside    *matchYaml   `yaml:"inside,omitempty"`
+	NotInside *matchYaml   `yaml:"not-inside,omitempty"`
+	Has       *matchYaml   `yaml:"has,omitempty"`
+	NotHas    *matchYaml   `yaml:"not-has,omitempty"`
+}
+
+func (m *matchYaml) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		m.Pattern = value.Value
+		return nil
+	}
+
+	type plainMatch matchYaml
+	return value.Decode((*plainMatch)(m))
+}
+
+// matchList is a list of match clauses, where a single clause is shorthand for a list of one.
+type matchList []*matchYaml
+
+func (l *matchList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.SequenceNode {
+		var m matchYaml
+		if err := value.Decode(&m); err != nil {
+			return err
+		}
+		*l = matchList{&m}
+		return nil
+	}
+
+	return value.Decode((*[]*matchYaml)(l))
+}
+
+type matchOp string
+
+const (
+	matchPattern   matchOp = "pattern"
+	matchAll       matchOp = "all"
+	matchAny       matchOp = "any"
+	matchNot       matchOp = "not"
+	matchInside    matchOp = "inside"
+	matchNotInside matchOp = "not-inside"
+	matchHas       matchOp = "has"
+	matchNotHas    matchOp = "not-has"
+)
+
+// MatchNode is a compiled node of the `match` tree of a YAML checker.
+// The tree is evaluated on the nodes a checker would report, and decides
+// whether they are reported.
+type MatchNode struct {
+	op matchOp
+	// the query of a pattern, and the name of the capture that must be the node it is evaluated on
+	query       *sitter.Query
+	captureName string
+	children    []*MatchNode
+
+	// the nodes the query of a pattern matches in the tree of matchedRoot, which
+	// are looked up by `inside` instead of running the query on each ancestor
+	mu          sync.Mutex
+	matchedRoot *sitter.Node
+	matched     map[*sitter.Node]struct{}
+}
+
+// compileMatch compiles a match tree for the checker named name. The patterns
+// that capture @name are the ones that find the nodes to report, other patterns
+// match the node they are evaluated on as a whole.
+func compileMatch(m *matchYaml, name string, lang *sitter.Language) (*MatchNode, error) {
+	if m == nil {
+		return nil, fmt.Errorf("empty match clause")
+	}
+
+	type operand struct {
+		op    matchOp
+		set   bool
+		match *matchYaml
+	}
+
+	operands := []operand{
+		{matchPattern, m.Pattern != "", nil},
+		{matchAll, m.All != nil, nil},
+		{matchAny, m.Any != nil, nil},
+		{matchNot, m.Not != nil, m.Not},
+		{matchInside, m.Inside != nil, m.Inside},
+		{matchNotInside, m.NotInside != nil, m.NotInside},
+		{matchHas, m.Has != nil, m.Has},
+		{matchNotHas, m.NotHas != nil, m.NotHas},
+	}
+
+	var chosen *operand
+	for i := range operands {
+		if !operands[i].set {
+			continue
+		}
+		if chosen != nil {
+			return nil, fmt.Errorf("match clause has both '%s' and '%s', use 'all' to combine them", chosen.op, operands[i].op)
+		}
+		chosen = &operands[i]
+	}
+
+	if chosen == nil {
+		return nil, fmt.Errorf("match clause needs one of 'pattern', 'all', 'any', 'not', 'inside', 'not-inside', 'has' or 'not-has'")
+	}
+
+	node := &MatchNode{op: chosen.op}
+	switch chosen.op {
+	case matchPattern:
+		query, err := sitter.NewQuery([]byte(m.Pattern), lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tree-sitter query in match pattern: %w", err)
+		}
+
+		node.query, node.captureName = query, name
+		if !queryHasCapture(query, name) {
+			// the pattern is grouped, so that the capture applies to all of it,
+			// including the predicates at its end
+			query, err = sitter.NewQuery([]byte("("+m.Pattern+") @"+filterPatternKey), lang)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tree-sitter query in match pattern: %w", err)
+			}
+			node.query, node.captureName = query, filterPatternKey
+		}
+
+	case matchAll, matchAny:
+		children := m.All
+		if chosen.op == matchAny {
+			children = m.Any
+		}
+
+		if len(children) == 0 {
+			return nil, fmt.Errorf("'%s' in match clause needs at least one clause", chosen.op)
+		}
+
+		for _, child := range children {
+			compiled, err := compileMatch(child, name, lang)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, compiled)
+		}
+
+	default:
+		compiled, err := compileMatch(chosen.match, name, lang)
+		if err != nil {
+			return nil, err
+		}
+		node.children = []*MatchNode{compiled}
+	}
+
+	return node, nil
+}
+
+// reportPatterns returns the queries of the patterns that find the nodes to report,
+// which are the patterns capturing the reported node that aren't under a negation
+// or a relation to another node.
+func (m *MatchNode) reportPatterns() []*sitter.Query {
+	switch m.op {
+	case matchPattern:
+		if m.captureName != filterPatternKey {
+			return []*sitter.Query{m.query}
+		}
+	case matchAll, matchAny:
+		var queries []*sitter.Query
+		for _, child := range m.children {
+			queries = append(queries, child.reportPatterns()...)
+		}
+		return queries
+	}
+
+	return nil
+}
+
+// Matches reports whether node satisfies the match tree.
+func (m *MatchNode) Matches(node *sitter.Node, source []byte) bool {
+	switch m.op {
+	case matchPattern:
+		return queryMatchesNode(m.query, m.captureName, node, source)
+
+	case matchAll:
+		for _, child := range m.children {
+			if !child.Matches(node, source) {
+				return false
+			}
+		}
+		return true
+
+	case matchAny:
+		for _, child := range m.children {
+			if child.Matches(node, source) {
+				return true
+			}
+		}
+		return false
+
+	case matchNot:
+		return !m.children[0].Matches(node, source)
+
+	case matchInside, matchNotInside:
+		inside := false
+		if child := m.children[0]; child.op == matchPattern {
+			matched := child.matchedNodes(node, source)
+			for parent := node.Parent(); parent != nil && !inside; parent = parent.Parent() {
+				_, inside = matched[parent]
+			}
+			return inside == (m.op == matchInside)
+		}
+
+		for parent := node.Parent(); parent != nil && !inside; parent = parent.Parent() {
+			inside = m.children[0].Matches(parent, source)
+		}
+		return inside == (m.op == matchInside)
+
+	case matchHas, matchNotHas:
+		has := false
+		if child := m.children[0]; child.op == matchPattern {
+			// a query finds the matches under the node in one pass
+			has = queryMatchesUnder(child.query, child.captureName, node, source)
+			return has == (m.op == matchHas)
+		}
+
+		for i := 0; i < int(node.NamedChildCount()) && !has; i++ {
+			has = m.children[0].matchesInTree(node.NamedChild(i), source)
+		}
+		return has == (m.op == matchHas)
+	}
+
+	return false
+}
+
+// matchesInTree reports whether node, or any node under it, satisfies the match tree.
+func (m *MatchNode) matchesInTree(node *sitter.Node, source []byte) bool {
+	if m.op == matchPattern {
+		// a query finds the matches in the tree in one pass
+		return queryMatchesIn(m.query, m.captureName, node, source)
+	}
+
+	if m.Matches(node, source) {
+		return true
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if m.matchesInTree(node.NamedChild(i), source) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchedNodes returns the nodes captured with the capture name of the pattern m in
+// the tree of node. The query runs once per tree, since running it on every ancestor of
+// the nodes it is checked against takes time quadratic in the size of the file.
+func (m *MatchNode) matchedNodes(node *sitter.Node, source []byte) map[*sitter.Node]struct{} {
+	root := node
+	for parent := root.Parent(); parent != nil; parent = parent.Parent() {
+		root = parent
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the root is kept with the nodes, which keeps its tree alive, so another
+	// tree can't have the same root
+	if m.matchedRoot == root {
+		return m.matched
+	}
+
+	matched := make(map[*sitter.Node]struct{})
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+
+	qc.Exec(m.query, root)
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+
+		match = qc.FilterPredicates(match, source)
+		for _, capture := range match.Captures {
+			if m.query.CaptureNameForId(capture.Index) == m.captureName {
+				matched[capture.Node] = struct{}{}
+			}
+		}
+	}
+
+	m.matchedRoot, m.matched = root, matched
+	return matched
+}
+
+// queryMatchesNode reports whether query has a match where captureName captures node.
+func queryMatchesNode(query *sitter.Query, captureName string, node *sitter.Node, source []byte) bool {
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+
+	qc.Exec(query, node)
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			return false
+		}
+
+		m = qc.FilterPredicates(m, source)
+		for _, capture := range m.Captures {
+			if capture.Node == node && query.CaptureNameForId(capture.Index) == captureName {
+				return true
+			}
+		}
+	}
+}
+
+// queryMatchesIn reports whether query has a match where captureName captures
+// node or a node under it.
+func queryMatchesIn(query *sitter.Query, captureName string, node *sitter.Node, source []byte) bool {
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+
+	qc.Exec(query, node)
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			return false
+		}
+
+		m = qc.FilterPredicates(m, source)
+		for _, capture := range m.Captures {
+			if query.CaptureNameForId(capture.Index) == captureName {
+				return true
+			}
+		}
+	}
+}
+
+// queryMatchesUnder reports whether query has a match where captureName captures
+// a node under node.
+func queryMatchesUnder(query *sitter.Query, captureName string, node *sitter.Node, source []byte) bool {
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+
+	qc.Exec(query, node)
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			return false
+		}
+
+		m = qc.FilterPredicates(m, source)
+		for _, capture := range m.Captures {
+			if capture.Node != node && query.CaptureNameForId(capture.Index) == captureName {
+				return true
+			}
+		}
+	}
+}
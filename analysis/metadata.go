@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+func (c Confidence) IsValid() bool {
+	switch c {
+	case ConfidenceHigh, ConfidenceMedium, ConfidenceLow:
+		return true
+	}
+	return false
+}
+
+// Metadata describes a checker beyond what it looks for, for compliance reports
+// and for selecting checkers in the config. All fields are optional.
+type Metadata struct {
+	// CWE are the IDs of the weaknesses the checker finds, like "CWE-295"
+	CWE []string `yaml:"cwe,omitempty" json:"cwe,omitempty"`
+	// OWASP are the OWASP Top 10 categories of the issues, like "A02:2021"
+	OWASP []string `yaml:"owasp,omitempty" json:"owasp,omitempty"`
+	// References are URLs with more details on the issues
+	References []string `yaml:"references,omitempty" json:"references,omitempty"`
+	// Confidence is how likely an issue is a true positive
+	Confidence Confidence `yaml:"confidence,omitempty" json:"confidence,omitempty"`
+	Tags       []string   `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Author     string     `yaml:"author,omitempty" json:"author,omitempty"`
+	Version    string     `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+var (
+	cweRegexp   = regexp.MustCompile(`^(?i:cwe-)?([0-9]+)$`)
+	owaspRegexp = regexp.MustCompile(`^A[0-9]{2}:[0-9]{4}$`)
+)
+
+// Normalize validates the metadata, and writes the CWE IDs in the "CWE-<number>" form.
+func (m *Metadata) Normalize() error {
+	var cwes []string
+	for _, cwe := range m.CWE {
+		parts := cweRegexp.FindStringSubmatch(strings.TrimSpace(cwe))
+		if parts == nil {
+			return fmt.Errorf("invalid CWE ID %q, expected a value like \"CWE-295\"", cwe)
+		}
+		cwes = append(cwes, "CWE-"+parts[1])
+	}
+	m.CWE = cwes
+
+	for _, category := range m.OWASP {
+		if !owaspRegexp.MatchString(category) {
+			return fmt.Errorf("invalid OWASP category %q, expected a value like \"A02:2021\"", category)
+		}
+	}
+
+	if m.Confidence != "" && !m.Confidence.IsValid() {
+		return fmt.Errorf("invalid confidence %q, expected 'high', 'medium' or 'low'", m.Confidence)
+	}
+
+	return nil
+}
+
+// IsEmpty reports whether no field of the metadata is set.
+func (m *Metadata) IsEmpty() bool {
+	return len(m.CWE) == 0 && len(m.OWASP) == 0 && len(m.References) == 0 &&
+		m.Confidence == "" && len(m.Tags) == 0 && m.Author == "" && m.Version == ""
+}
+
+// Fields returns the values of the metadata by selector key, like "cwe" and "tag",
+// for the checker selectors in the config.
+func (m *Metadata) Fields() map[string][]string {
+	fields := map[string][]string{
+		"cwe":   m.CWE,
+		"owasp": m.OWASP,
+		"tag":   m.Tags,
+	}
+
+	if m.Confidence != "" {
+		fields["confidence"] = []string{string(m.Confidence)}
+	}
+	if m.Author != "" {
+		fields["author"] = []string{m.Author}
+	}
+	if m.Version != "" {
+		fields["version"] = []string{m.Version}
+	}
+
+	return fields
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata Metadata
+		wantCWE  []string
+		wantErr  bool
+	}{
+		{
+			name:     "CWE IDs",
+			metadata: Metadata{CWE: []string{"295", "cwe-22", "CWE-79"}},
+			wantCWE:  []string{"CWE-295", "CWE-22", "CWE-79"},
+		},
+		{
+			name:     "invalid CWE ID",
+			metadata: Metadata{CWE: []string{"XSS"}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid OWASP category",
+			metadata: Metadata{OWASP: []string{"A2-2021"}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid confidence",
+			metadata: Metadata{Confidence: "certain"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metadata.Normalize()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCWE, tt.metadata.CWE)
+		})
+	}
+}
+
+func TestYamlMetadata(t *testing.T) {
+	checker := `language: py
+name: weak-hash
+message: "md5 is a weak hash"
+category: security
+severity: warning
+code-pattern: hashlib.md5($DATA)
+metadata:
+  cwe: [328]
+  owasp: ["A02:2021"]
+  confidence: medium
+  tags: [crypto]
+  references: [https://cwe.mitre.org/data/definitions/328.html]
+`
+
+	analyzer, _, err := ReadFromBytes([]byte(checker))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CWE-328"}, analyzer.Metadata.CWE)
+
+	_, _, err = ReadFromBytes([]byte(checker + "  author: [someone]\n"))
+	require.Error(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.py")
+	require.NoError(t, os.WriteFile(path, []byte("import hashlib\n\nhashlib.md5(data)\n"), 0644))
+
+	issues, err := RunAnalyzers(dir, []*Analyzer{&analyzer}, nil)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, analyzer.Metadata, issues[0].Metadata)
+
+	t.Run("json", func(t *testing.T) {
+		out, err := issues[0].AsJson()
+		require.NoError(t, err)
+
+		decoded, err := IssueFromJson(out)
+		require.NoError(t, err)
+		assert.Equal(t, analyzer.Metadata, decoded.Metadata)
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		out, err := ReportIssues(issues, "sarif")
+		require.NoError(t, err)
+
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(out, &log))
+		require.Len(t, log.Runs[0].Tool.Driver.Rules, 1)
+
+		rule := log.Runs[0].Tool.Driver.Rules[0]
+		assert.Equal(t, "weak-hash", rule.Id)
+		assert.Equal(t, "https://cwe.mitre.org/data/definitions/328.html", rule.HelpUri)
+		require.NotNil(t, rule.Properties)
+		assert.Equal(t, "medium", rule.Properties.Precision)
+		assert.Equal(t, []string{"crypto", "external/cwe/cwe-328", "external/owasp/a02:2021"}, rule.Properties.Tags)
+	})
+}
@@ -0,0 +1,209 @@
+package analysis
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// the subset of SARIF 2.1.0 that issues are reported in
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	Id         string               `json:"id"`
+	HelpUri    string               `json:"helpUri,omitempty"`
+	Properties *sarifRuleProperties `json:"properties,omitempty"`
+}
+
+type sarifRuleProperties struct {
+	Tags       []string `json:"tags,omitempty"`
+	Precision  string   `json:"precision,omitempty"`
+	References []string `json:"references,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	Version    string   `json:"version,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+	// ByteOffset isn't omitted when empty, since 0 is the start of the file
+	ByteOffset *uint32 `json:"byteOffset,omitempty"`
+	ByteLength *uint32 `json:"byteLength,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifResult struct {
+	RuleId              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Fixes               []sarifFix        `json:"fixes,omitempty"`
+}
+
+// sarifLevel maps the severity of an issue to a SARIF result level.
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityCritical, SeverityError:
+		return "error"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// sarifRuleFromMetadata returns the rule of a checker with its metadata. The CWE IDs and
+// OWASP categories are tags, in the form code scanning tools like GitHub's recognize.
+func sarifRuleFromMetadata(id string, metadata Metadata) sarifRule {
+	rule := sarifRule{Id: id}
+	if metadata.IsEmpty() {
+		return rule
+	}
+
+	properties := &sarifRuleProperties{
+		Precision:  string(metadata.Confidence),
+		References: metadata.References,
+		Author:     metadata.Author,
+		Version:    metadata.Version,
+	}
+
+	properties.Tags = append(properties.Tags, metadata.Tags...)
+	for _, cwe := range metadata.CWE {
+		properties.Tags = append(properties.Tags, "external/cwe/"+strings.ToLower(cwe))
+	}
+	for _, category := range metadata.OWASP {
+		properties.Tags = append(properties.Tags, "external/owasp/"+strings.ToLower(category))
+	}
+
+	if len(metadata.References) > 0 {
+		rule.HelpUri = metadata.References[0]
+	}
+	rule.Properties = properties
+	return rule
+}
+
+func reportSARIF(issues []*Issue) ([]byte, error) {
+	results := []sarifResult{}
+	var rules []sarifRule
+	ruleSeen := make(map[string]bool)
+	for _, issue := range issues {
+		if issue.Id != nil && !ruleSeen[*issue.Id] {
+			ruleSeen[*issue.Id] = true
+			rules = append(rules, sarifRuleFromMetadata(*issue.Id, issue.Metadata))
+		}
+
+		uri := filepath.ToSlash(issue.Filepath)
+		r := issue.Range()
+
+		result := sarifResult{
+			Level:   sarifLevel(issue.Severity),
+			Message: sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{Uri: uri},
+					Region: sarifRegion{
+						// SARIF lines and columns are 1-indexed
+						StartLine:   int(r.StartPoint.Row) + 1,
+						StartColumn: int(r.StartPoint.Column) + 1,
+						EndLine:     int(r.EndPoint.Row) + 1,
+						EndColumn:   int(r.EndPoint.Column) + 1,
+					},
+				},
+			}},
+		}
+
+		if issue.Id != nil {
+			result.RuleId = *issue.Id
+		}
+
+		if issue.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{"globstar/v1": issue.Fingerprint}
+		}
+
+		for _, fix := range issue.Fixes {
+			change := sarifArtifactChange{ArtifactLocation: sarifArtifactLocation{Uri: uri}}
+			for _, edit := range fix.Edits {
+				offset, length := edit.StartByte, edit.EndByte-edit.StartByte
+				change.Replacements = append(change.Replacements, sarifReplacement{
+					DeletedRegion:   sarifRegion{ByteOffset: &offset, ByteLength: &length},
+					InsertedContent: sarifMessage{Text: edit.NewText},
+				})
+			}
+
+			result.Fixes = append(result.Fixes, sarifFix{
+				Description:     sarifMessage{Text: fix.Description},
+				ArtifactChanges: []sarifArtifactChange{change},
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "globstar",
+				InformationUri: "https://globstar.dev",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}, "", "  ")
+}
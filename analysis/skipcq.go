@@ -0,0 +1,281 @@
+package analysis
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// SkipKind is the kind of a skipcq comment, which decides the code it applies to.
+type SkipKind string
+
+const (
+	// SkipLine is a `skipcq` comment. It applies to the line it is on and, when it
+	// is on a line of its own, to the whole statement or function that follows it.
+	SkipLine SkipKind = "skipcq"
+	// SkipFile is a `skipcq-file` comment, which applies to the whole file.
+	SkipFile SkipKind = "skipcq-file"
+	// SkipRegion is a `skipcq-begin` comment. It applies to all lines up to
+	// the matching `skipcq-end` comment, or to the end of the file.
+	SkipRegion SkipKind = "skipcq-begin"
+)
+
+// for caching the skipcq comments
+type SkipComment struct {
+	// the line number for the skipcq comment
+	CommentLine int
+	// the entire text of the skipcq comment
+	CommentText string
+	// (optional) name of the checker for targetted skip
+	CheckerIds []string
+	Kind       SkipKind
+	// the first and last line (0-indexed, inclusive) of the code the comment applies to
+	StartLine int
+	EndLine   int
+	// the comment node
+	Node *sitter.Node
+	// the issues the comment suppressed, in the runs on the file so far
+	Suppressed []*Issue
+	// (optional) why the issues are suppressed, from reason="..."
+	Reason string
+	// (optional) who is responsible for the suppression, from owner=...
+	Owner string
+	// (optional) the date the comment stops applying on, from until=YYYY-MM-DD
+	Until string
+}
+
+// skipAttrRegexp matches the key=value attributes of a skipcq comment.
+// Values with spaces must be quoted.
+var skipAttrRegexp = regexp.MustCompile(`(?i)\b(reason|owner|until)=(?:"([^"]*)"|'([^']*)'|(\S+))`)
+
+// Expired reports whether the comment has an expiry date that is on or before now.
+// An until value that isn't a YYYY-MM-DD date counts as expired, so that a typo
+// doesn't suppress issues forever.
+func (comment *SkipComment) Expired(now time.Time) bool {
+	if comment.Until == "" {
+		return false
+	}
+
+	until, err := time.ParseInLocation(time.DateOnly, comment.Until, now.Location())
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(until)
+}
+
+// SkipComments returns the skipcq comments in the file. They are gathered once, and
+// shared by all analyzer runs on the file, so that SkipComment.Suppressed adds up the
+// issues suppressed in each of them.
+//
+// The code embedded in a file shares the skipcq comments of the file.
+func (file *ParseResult) SkipComments() []*SkipComment {
+	if file.Host != nil {
+		return file.Host.SkipComments()
+	}
+
+	if file.skipComments == nil {
+		file.skipComments = GatherSkipInfo(file)
+		if file.skipComments == nil {
+			file.skipComments = []*SkipComment{}
+		}
+	}
+	return file.skipComments
+}
+
+// skipRegexp matches skipcq comments, after the comment delimiters are removed
+var skipRegexp = regexp.MustCompile(`(?i)\bskipcq(?:-(?P<kind>file|begin|end))?\b(?::(?:\s*(?P<issue_ids>([A-Za-z\-_0-9]*(?:,\s*)?)+))?)?`)
+
+// cache all the skipcq comments from an ast
+func GatherSkipInfo(fileContext *ParseResult) []*SkipComment {
+	var skipLines []*SkipComment
+
+	syntax := fileContext.Language.CommentSyntax()
+	root := fileContext.Ast
+	lastLine := int(root.EndPoint().Row)
+
+	// the skipcq-begin comments that haven't been closed yet
+	var openRegions []*SkipComment
+
+	// gather all skipcq comment lines in a single pass
+	for _, commentNode := range commentNodes(fileContext) {
+		commentText, commentStart, commentLine := trimComment(commentNode, fileContext.Source)
+
+		commentBody, ok := syntax.CommentBody(commentText)
+		if !ok {
+			continue
+		}
+
+		// the attributes are removed before looking for checker IDs, which
+		// would otherwise include the attribute names
+		attrs := skipAttrRegexp.FindAllStringSubmatch(commentBody, -1)
+		matches := skipRegexp.FindStringSubmatch(skipAttrRegexp.ReplaceAllString(commentBody, ""))
+		if matches == nil {
+			continue
+		}
+
+		var checkerIds []string
+		issueIdsIdx := skipRegexp.SubexpIndex("issue_ids")
+		if issueIdsIdx != -1 && issueIdsIdx < len(matches) && matches[issueIdsIdx] != "" {
+			idSlice := strings.Split(matches[issueIdsIdx], ",")
+			for _, id := range idSlice {
+				trimmedId := strings.TrimSpace(id)
+				if trimmedId != "" {
+					checkerIds = append(checkerIds, trimmedId)
+				}
+			}
+		}
+
+		skipComment := &SkipComment{
+			CommentLine: commentLine,
+			CommentText: commentText,
+			CheckerIds:  checkerIds, // will be empty for generic skipcq
+			Node:        commentNode,
+		}
+
+		for _, attr := range attrs {
+			value := attr[2] + attr[3] + attr[4]
+			switch strings.ToLower(attr[1]) {
+			case "reason":
+				skipComment.Reason = value
+			case "owner":
+				skipComment.Owner = value
+			case "until":
+				skipComment.Until = value
+			}
+		}
+
+		switch strings.ToLower(matches[skipRegexp.SubexpIndex("kind")]) {
+		case "file":
+			skipComment.Kind = SkipFile
+			skipComment.StartLine = 0
+			skipComment.EndLine = lastLine
+		case "begin":
+			skipComment.Kind = SkipRegion
+			skipComment.StartLine = commentLine
+			skipComment.EndLine = lastLine
+			openRegions = append(openRegions, skipComment)
+		case "end":
+			// closes the innermost open region
+			if len(openRegions) > 0 {
+				openRegions[len(openRegions)-1].EndLine = commentLine
+				openRegions = openRegions[:len(openRegions)-1]
+			}
+			continue
+		default:
+			skipComment.Kind = SkipLine
+			skipComment.StartLine, skipComment.EndLine = skipCommentLines(root, commentNode, commentStart, commentLine, fileContext.Source)
+		}
+
+		skipLines = append(skipLines, skipComment)
+	}
+
+	return skipLines
+}
+
+// skipCommentLines returns the lines a `skipcq` comment applies to.
+//
+// A comment after code applies to its line, and to the whole statement that ends on it.
+// A comment on a line of its own applies to the line after it, and to the whole
+// node that starts on that line, so that a comment above a multi-line call or a
+// function covers all of it.
+// commentStart and commentLine are where the comment text starts.
+func skipCommentLines(root, comment *sitter.Node, commentStart uint32, commentLine int, source []byte) (int, int) {
+	lineStart := bytes.LastIndexByte(source[:commentStart], '\n') + 1
+	if strings.TrimSpace(string(source[lineStart:commentStart])) != "" {
+		startLine := commentLine
+		if prev := comment.PrevNamedSibling(); prev != nil && int(prev.EndPoint().Row) == commentLine {
+			startLine = min(startLine, int(prev.StartPoint().Row))
+		}
+		return startLine, commentLine
+	}
+
+	endLine := commentLine + 1
+	if target := nextCodeNode(root, comment.EndByte()); target != nil && int(target.StartPoint().Row) == commentLine+1 {
+		target = statementAt(target)
+		endLine = max(endLine, int(target.EndPoint().Row))
+	}
+
+	return commentLine, endLine
+}
+
+// nextCodeNode returns the outermost named node that starts at or after offset,
+// skipping comments.
+func nextCodeNode(node *sitter.Node, offset uint32) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.EndByte() <= offset || isCommentNode(child) {
+			continue
+		}
+
+		if child.StartByte() >= offset {
+			return child
+		}
+
+		if found := nextCodeNode(child, offset); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// statementAt narrows down a node to the first statement in it, for nodes like
+// blocks that hold a sequence of statements and start where the first one does.
+func statementAt(node *sitter.Node) *sitter.Node {
+	for {
+		child := node.NamedChild(0)
+		if child == nil || child.StartByte() != node.StartByte() {
+			return node
+		}
+
+		next := child.NextNamedSibling()
+		if next == nil || next.StartPoint().Row <= child.EndPoint().Row {
+			return node
+		}
+
+		node = child
+	}
+}
+
+// ContainsSkipcq reports whether a skipcq comment in skipLines applies to issue.
+func ContainsSkipcq(skipLines []*SkipComment, issue *Issue) bool {
+	return len(matchingSkipcqs(skipLines, issue)) > 0
+}
+
+// matchingSkipcqs returns all skipcq comments in skipLines that apply to issue.
+func matchingSkipcqs(skipLines []*SkipComment, issue *Issue) []*SkipComment {
+	if len(skipLines) == 0 {
+		return nil
+	}
+
+	nodeLine := int(issue.Range().StartPoint.Row)
+
+	var checkerId string
+	if issue.Id != nil {
+		checkerId = *issue.Id
+	}
+
+	now := time.Now()
+	var matching []*SkipComment
+	for _, comment := range skipLines {
+		if nodeLine < comment.StartLine || nodeLine > comment.EndLine {
+			continue
+		}
+
+		// the issues suppressed by an expired comment are reported again
+		if comment.Expired(now) {
+			continue
+		}
+
+		if len(comment.CheckerIds) == 0 || slices.Contains(comment.CheckerIds, checkerId) {
+			matching = append(matching, comment)
+		}
+	}
+
+	return matching
+}
@@ -0,0 +1,565 @@
+package analysis
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TaintSpec is the nodes of a file that the taint analysis tracks data between:
+// tainted data comes from the sources, and must not reach the sinks without going
+// through a sanitizer.
+type TaintSpec struct {
+	// Sources are the nodes that produce tainted data. A source that is a variable,
+	// like a parameter, taints the variable from there on.
+	Sources map[*sitter.Node]bool
+	// Sinks are the nodes that tainted data must not reach
+	Sinks map[*sitter.Node]bool
+	// Sanitizers are the nodes whose value is never tainted, like a call that escapes its argument
+	Sanitizers map[*sitter.Node]bool
+	// Propagators are the flows of tainted data other than assignments and expressions
+	Propagators []TaintPropagator
+}
+
+// TaintPropagator is a flow of tainted data from a node to another that isn't an
+// assignment, like from the argument of items.append(x) to items.
+type TaintPropagator struct {
+	From *sitter.Node
+	To   *sitter.Node
+}
+
+// TaintFlow is a flow of tainted data from a source to a sink.
+type TaintFlow struct {
+	Source *sitter.Node
+	Sink   *sitter.Node
+}
+
+// assignmentSyntax is the fields of the target and the value of an assignment
+type assignmentSyntax struct {
+	target, value string
+	// augmented assignments and loop variables only ever add taint to their target,
+	// since the target keeps the taint it had, or may not be assigned at all
+	augmented bool
+}
+
+// taintAssignments are the nodes that assign values to variables, by language
+var taintAssignments = map[Language]map[string]assignmentSyntax{
+	LangPy: {
+		"assignment":           {"left", "right", false},
+		"augmented_assignment": {"left", "right", true},
+		"named_expression":     {"name", "value", false},
+		"for_statement":        {"left", "right", true},
+		"for_in_clause":        {"left", "right", true},
+	},
+	LangGo: {
+		"assignment_statement":  {"left", "right", false},
+		"short_var_declaration": {"left", "right", false},
+		"var_spec":              {"name", "value", false},
+		"range_clause":          {"left", "right", true},
+	},
+	LangJs:  jsTaintAssignments,
+	LangTs:  jsTaintAssignments,
+	LangTsx: jsTaintAssignments,
+	LangJava: {
+		"variable_declarator":    {"name", "value", false},
+		"assignment_expression":  {"left", "right", false},
+		"enhanced_for_statement": {"name", "value", true},
+	},
+}
+
+var jsTaintAssignments = map[string]assignmentSyntax{
+	"variable_declarator":             {"name", "value", false},
+	"assignment_expression":           {"left", "right", false},
+	"augmented_assignment_expression": {"left", "right", true},
+	"for_in_statement":                {"left", "right", true},
+}
+
+// taintFunctions are the nodes whose bodies are analyzed on their own, by language
+var taintFunctions = map[Language][]string{
+	LangPy:   {"function_definition", "lambda"},
+	LangGo:   {"function_declaration", "method_declaration", "func_literal"},
+	LangJs:   jsTaintFunctions,
+	LangTs:   jsTaintFunctions,
+	LangTsx:  jsTaintFunctions,
+	LangJava: {"method_declaration", "constructor_declaration", "lambda_expression"},
+}
+
+var jsTaintFunctions = []string{
+	"function_declaration", "function_expression", "function", "arrow_function",
+	"method_definition", "generator_function_declaration", "generator_function",
+}
+
+var (
+	// conditionalNodeRegexp matches the node types (across grammars) of the code that
+	// may not run, where an assignment doesn't clear the taint of its target
+	conditionalNodeRegexp = regexp.MustCompile(`(^|_)(if|elif|else|for|while|do|loop|switch|case|select|match|try|catch|except|finally|conditional|ternary)(_|$)`)
+	// loopNodeRegexp matches the node types of loops, which are analyzed twice so that
+	// taint flows from the end of the body to its start
+	loopNodeRegexp = regexp.MustCompile(`(^|_)(for|while|do|loop)(_|$)`)
+	// accessPathRegexp matches the variables and fields tracked by the taint
+	// analysis, like "data" and "self.data"
+	accessPathRegexp = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+)
+
+// the fields of a node that name a member or an argument rather than a variable
+var memberFields = []string{"attribute", "property", "field", "name", "key"}
+
+// the node types of member accesses that are tracked like variables
+var memberNodeTypes = []string{"attribute", "member_expression", "selector_expression", "field_access"}
+
+// FindTaintFlows finds the sinks of spec that tainted data reaches in file, with the
+// source of the data.
+//
+// The analysis is intraprocedural: the functions of the file are analyzed on their own,
+// in the order of their statements, starting with the variables tainted where they are
+// defined. Data is tainted when it comes from a source, a tainted variable, or an
+// expression with a tainted part, like a concatenation or a call with a tainted argument.
+// Variables are tracked by name, along with fields like self.data. The assignments of
+// variables are known for Python, Go, JavaScript, TypeScript and Java, and other
+// languages only have the flows within expressions.
+func FindTaintFlows(file *ParseResult, spec *TaintSpec) []TaintFlow {
+	a := &taintAnalysis{
+		spec:        spec,
+		source:      file.Source,
+		assignments: taintAssignments[file.Language],
+		functions:   taintFunctions[file.Language],
+		scope:       file.Ast,
+		tainted:     make(map[string]*sitter.Node),
+		marked:      make(map[*sitter.Node]*sitter.Node),
+		flows:       make(map[*sitter.Node]*sitter.Node),
+	}
+
+	a.propagators = make(map[*sitter.Node][]TaintPropagator)
+	for _, propagator := range spec.Propagators {
+		a.propagators[propagator.From] = append(a.propagators[propagator.From], propagator)
+	}
+
+	a.visit(file.Ast)
+
+	var flows []TaintFlow
+	for sink, source := range a.flows {
+		flows = append(flows, TaintFlow{Source: source, Sink: sink})
+	}
+	slices.SortFunc(flows, func(x, y TaintFlow) int {
+		return int(x.Sink.StartByte()) - int(y.Sink.StartByte())
+	})
+
+	return flows
+}
+
+type taintAnalysis struct {
+	spec        *TaintSpec
+	source      []byte
+	assignments map[string]assignmentSyntax
+	functions   []string
+	propagators map[*sitter.Node][]TaintPropagator
+	// the function being analyzed, or the root of the file
+	scope *sitter.Node
+	// the tainted variables, by access path, with the source of their taint
+	tainted map[string]*sitter.Node
+	// the nodes tainted by propagators that aren't variables
+	marked map[*sitter.Node]*sitter.Node
+	// the sinks reached by tainted data, with its source
+	flows map[*sitter.Node]*sitter.Node
+}
+
+// visit analyzes node and the nodes under it, in the order they run.
+func (a *taintAnalysis) visit(node *sitter.Node) {
+	if node != a.scope && slices.Contains(a.functions, node.Type()) {
+		// a function starts with the variables tainted where it is defined
+		inner := *a
+		inner.scope = node
+		inner.tainted = maps.Clone(a.tainted)
+		inner.visitChildren(node, nil)
+		return
+	}
+
+	passes := 1
+	if loopNodeRegexp.MatchString(node.Type()) {
+		passes = 2
+	}
+
+	for range passes {
+		assignment, ok := a.assignments[node.Type()]
+		if !ok {
+			a.visitChildren(node, nil)
+			continue
+		}
+
+		// the value is evaluated before it is assigned, and before the body of a loop
+		value := node.ChildByFieldName(assignment.value)
+		if value != nil {
+			a.visit(value)
+		}
+		a.assign(node, assignment, value)
+		a.visitChildren(node, value)
+	}
+
+	a.afterVisit(node)
+}
+
+func (a *taintAnalysis) visitChildren(node, skip *sitter.Node) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child != skip {
+			a.visit(child)
+		}
+	}
+}
+
+// afterVisit records the taint that node introduces, and the flow to node if it is a sink.
+func (a *taintAnalysis) afterVisit(node *sitter.Node) {
+	if a.spec.Sources[node] {
+		if key, ok := a.variable(node); ok {
+			a.tainted[key] = node
+		}
+	}
+
+	for _, propagator := range a.propagators[node] {
+		source := a.taintOf(propagator.From)
+		if source == nil {
+			continue
+		}
+
+		if key, ok := a.accessPath(propagator.To); ok {
+			a.tainted[key] = source
+		} else {
+			a.marked[propagator.To] = source
+		}
+	}
+
+	if a.spec.Sinks[node] {
+		if _, seen := a.flows[node]; !seen {
+			if source := a.taintOf(node); source != nil {
+				a.flows[node] = source
+			}
+		}
+	}
+}
+
+// assign updates the taint of the targets of an assignment with the taint of its value.
+func (a *taintAnalysis) assign(node *sitter.Node, assignment assignmentSyntax, value *sitter.Node) {
+	target := node.ChildByFieldName(assignment.target)
+	if target == nil {
+		return
+	}
+
+	var source *sitter.Node
+	if value != nil {
+		source = a.taintOf(value)
+	}
+
+	augmented := assignment.augmented
+	if operator := node.ChildByFieldName("operator"); operator != nil {
+		op := operator.Content(a.source)
+		augmented = augmented || (op != "=" && op != ":=")
+	}
+
+	for _, key := range a.targets(target) {
+		switch {
+		case source != nil:
+			a.tainted[key] = source
+		case !augmented && a.unconditional(node):
+			// a variable assigned clean data is clean, along with its fields
+			for tainted := range a.tainted {
+				if tainted == key || strings.HasPrefix(tainted, key+".") {
+					delete(a.tainted, tainted)
+				}
+			}
+		}
+	}
+}
+
+// targets returns the access paths of the variables assigned by target, which can be a
+// list or a pattern of variables. An assignment to an element assigns its container.
+func (a *taintAnalysis) targets(target *sitter.Node) []string {
+	if key, ok := a.variable(target); ok {
+		return []string{key}
+	}
+
+	if strings.Contains(target.Type(), "subscript") || strings.Contains(target.Type(), "index") {
+		if container := target.NamedChild(0); container != nil {
+			return a.targets(container)
+		}
+		return nil
+	}
+
+	var keys []string
+	for i := 0; i < int(target.NamedChildCount()); i++ {
+		keys = append(keys, a.targets(target.NamedChild(i))...)
+	}
+	return keys
+}
+
+// unconditional reports whether node always runs when the function being analyzed runs.
+func (a *taintAnalysis) unconditional(node *sitter.Node) bool {
+	for parent := node.Parent(); parent != nil && parent != a.scope; parent = parent.Parent() {
+		if conditionalNodeRegexp.MatchString(parent.Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// taintOf returns the source of the taint of node, or nil when node isn't tainted.
+func (a *taintAnalysis) taintOf(node *sitter.Node) *sitter.Node {
+	if a.spec.Sanitizers[node] {
+		return nil
+	}
+	if a.spec.Sources[node] {
+		return node
+	}
+	if source, ok := a.marked[node]; ok {
+		return source
+	}
+	if key, ok := a.accessPath(node); ok {
+		if source, ok := a.tainted[key]; ok {
+			return source
+		}
+	}
+
+	// a function isn't tainted by the data it uses
+	if slices.Contains(a.functions, node.Type()) {
+		return nil
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if source := a.taintOf(node.NamedChild(i)); source != nil {
+			return source
+		}
+	}
+	return nil
+}
+
+// accessPath returns the access path of node, like "data" or "self.data", when node
+// is a variable or a field of one.
+func (a *taintAnalysis) accessPath(node *sitter.Node) (string, bool) {
+	if node.Type() != "identifier" && !slices.Contains(memberNodeTypes, node.Type()) {
+		return "", false
+	}
+
+	// the name of a member or of a keyword argument isn't a variable
+	if parent := node.Parent(); parent != nil && node.Type() == "identifier" {
+		for _, field := range memberFields {
+			if parent.ChildByFieldName(field) == node {
+				return "", false
+			}
+		}
+	}
+
+	key := normalizeSource(node.Content(a.source))
+	if !accessPathRegexp.MatchString(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// variable returns the access path of node like accessPath, or its name when node
+// declares a variable, like a parameter or the name of a declaration.
+func (a *taintAnalysis) variable(node *sitter.Node) (string, bool) {
+	if key, ok := a.accessPath(node); ok {
+		return key, true
+	}
+
+	if strings.HasSuffix(node.Type(), "identifier") || strings.HasSuffix(node.Type(), "identifier_pattern") {
+		return node.Content(a.source), true
+	}
+	return "", false
+}
+
+// Mode is how a YAML checker finds the code it reports.
+type Mode string
+
+const (
+	// ModeSearch reports the matches of the patterns
+	ModeSearch Mode = "search"
+	// ModeTaint reports the sinks that data from the sources reaches, see FindTaintFlows
+	ModeTaint Mode = "taint"
+)
+
+func (m Mode) IsValid() bool {
+	switch m {
+	case ModeSearch, ModeTaint:
+		return true
+	}
+	return false
+}
+
+// TaintPatterns are the compiled patterns of a YAML checker in taint mode. The node
+// of a pattern in Sources, Sinks and Sanitizers is its @source, @sink or @sanitizer
+// capture, or the whole pattern when it has none. Propagators have @from and @to captures.
+type TaintPatterns struct {
+	Sources     []*sitter.Query
+	Sinks       []*sitter.Query
+	Sanitizers  []*sitter.Query
+	Propagators []*sitter.Query
+}
+
+// compileTaint compiles the patterns of a checker in taint mode.
+func compileTaint(taint Yaml, lang *sitter.Language) (*TaintPatterns, error) {
+	if len(taint.Sources) == 0 || len(taint.Sinks) == 0 {
+		return nil, fmt.Errorf("taint mode needs 'sources' and 'sinks'")
+	}
+
+	var err error
+	patterns := &TaintPatterns{}
+	if patterns.Sources, err = compileTaintPatterns(taint.Sources, "source", lang); err != nil {
+		return nil, err
+	}
+	if patterns.Sinks, err = compileTaintPatterns(taint.Sinks, "sink", lang); err != nil {
+		return nil, err
+	}
+	if patterns.Sanitizers, err = compileTaintPatterns(taint.Sanitizers, "sanitizer", lang); err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range taint.Propagators {
+		query, err := sitter.NewQuery([]byte(pattern), lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tree-sitter query in propagators: %w", err)
+		}
+		if !queryHasCapture(query, "from") || !queryHasCapture(query, "to") {
+			return nil, fmt.Errorf("propagator patterns need @from and @to captures")
+		}
+		patterns.Propagators = append(patterns.Propagators, query)
+	}
+
+	return patterns, nil
+}
+
+// compileTaintPatterns compiles the patterns of a part of a taint spec, where the
+// node of a pattern is its capture named after the part, or the whole pattern.
+func compileTaintPatterns(patterns []string, part string, lang *sitter.Language) ([]*sitter.Query, error) {
+	var queries []*sitter.Query
+	for _, pattern := range patterns {
+		query, err := sitter.NewQuery([]byte(pattern), lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tree-sitter query in %ss: %w", part, err)
+		}
+
+		if !queryHasCapture(query, part) {
+			// the pattern is grouped, so that the capture applies to all of it
+			query, err = sitter.NewQuery([]byte("("+pattern+") @"+part), lang)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tree-sitter query in %ss: %w", part, err)
+			}
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// taintMatch is a match of a sink pattern, to interpolate its captures in the message
+type taintMatch struct {
+	query    *sitter.Query
+	captures []sitter.QueryCapture
+}
+
+// spec finds the nodes of the patterns in file.
+func (t *TaintPatterns) spec(file *ParseResult) (*TaintSpec, map[*sitter.Node]taintMatch) {
+	spec := &TaintSpec{
+		Sources:    make(map[*sitter.Node]bool),
+		Sinks:      make(map[*sitter.Node]bool),
+		Sanitizers: make(map[*sitter.Node]bool),
+	}
+	sinkMatches := make(map[*sitter.Node]taintMatch)
+
+	parts := []struct {
+		queries []*sitter.Query
+		capture string
+		nodes   map[*sitter.Node]bool
+	}{
+		{t.Sources, "source", spec.Sources},
+		{t.Sinks, "sink", spec.Sinks},
+		{t.Sanitizers, "sanitizer", spec.Sanitizers},
+	}
+
+	for _, part := range parts {
+		for _, query := range part.queries {
+			eachMatch(query, file, func(captures []sitter.QueryCapture) {
+				for _, capture := range captures {
+					if query.CaptureNameForId(capture.Index) != part.capture {
+						continue
+					}
+					part.nodes[capture.Node] = true
+					if part.capture == "sink" {
+						if _, ok := sinkMatches[capture.Node]; !ok {
+							sinkMatches[capture.Node] = taintMatch{query, captures}
+						}
+					}
+				}
+			})
+		}
+	}
+
+	for _, query := range t.Propagators {
+		eachMatch(query, file, func(captures []sitter.QueryCapture) {
+			var propagator TaintPropagator
+			for _, capture := range captures {
+				switch query.CaptureNameForId(capture.Index) {
+				case "from":
+					propagator.From = capture.Node
+				case "to":
+					propagator.To = capture.Node
+				}
+			}
+			if propagator.From != nil && propagator.To != nil {
+				spec.Propagators = append(spec.Propagators, propagator)
+			}
+		})
+	}
+
+	return spec, sinkMatches
+}
+
+// runTaint reports the sinks that tainted data reaches in the file of pass.
+func (ana *YamlAnalyzer) runTaint(pass *Pass) {
+	file := pass.FileContext
+	spec, sinkMatches := ana.Taint.spec(file)
+	if len(spec.Sources) == 0 || len(spec.Sinks) == 0 {
+		return
+	}
+
+	reported := make(map[*sitter.Node]bool)
+	for _, flow := range FindTaintFlows(file, spec) {
+		node := flow.Sink
+		if ana.ReportRange == ReportRangeName {
+			node = nameNode(node)
+		}
+		if reported[node] {
+			continue
+		}
+		reported[node] = true
+
+		match := sinkMatches[flow.Sink]
+		message := interpolateCaptures(ana.Message, match.query, match.captures, file.Source)
+
+		if ana.ReportRange == ReportRangeLine && pass.ReportRange != nil {
+			pass.ReportRange(pass, node, lineRange(node, file.Source), message)
+		} else {
+			pass.Report(pass, node, message)
+		}
+	}
+}
+
+// eachMatch calls fn with the captures of each match of query in file.
+func eachMatch(query *sitter.Query, file *ParseResult, fn func([]sitter.QueryCapture)) {
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+
+	qc.Exec(query, file.Ast)
+	for {
+		m, ok := qc.NextMatch()
+		if !ok {
+			return
+		}
+
+		m = qc.FilterPredicates(m, file.Source)
+		if len(m.Captures) > 0 {
+			fn(m.Captures)
+		}
+	}
+}
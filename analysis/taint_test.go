@@ -0,0 +1,214 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlTaint(t *testing.T) {
+	pyChecker := `language: py
+name: command-injection
+message: "@fn runs a command with user input"
+category: security
+severity: error
+mode: taint
+sources:
+  - '(call function: (identifier) @fn (#eq? @fn "input")) @source'
+sinks:
+  - '(call function: (attribute attribute: (identifier) @fn (#eq? @fn "system"))) @sink'
+sanitizers:
+  - '(call function: (attribute attribute: (identifier) @fn (#eq? @fn "quote")))'
+propagators:
+  - >
+    (call
+      function: (attribute object: (identifier) @to attribute: (identifier) @fn)
+      arguments: (argument_list (_) @from)
+      (#eq? @fn "append"))
+`
+
+	tests := []struct {
+		name    string
+		checker string
+		file    string
+		source  string
+		want    []int
+	}{
+		{
+			name:    "through variables and expressions",
+			checker: pyChecker,
+			file:    "app.py",
+			source: `def run():
+    name = input()
+    command = "echo " + name
+    os.system(command)
+    os.system("echo {}".format(name))
+    os.system("ls")
+`,
+			want: []int{4, 5},
+		},
+		{
+			name:    "sanitized",
+			checker: pyChecker,
+			file:    "app.py",
+			source: `def run():
+    name = input()
+    os.system("echo " + shlex.quote(name))
+    name = shlex.quote(name)
+    os.system("echo " + name)
+`,
+		},
+		{
+			name:    "conditional assignment keeps the taint",
+			checker: pyChecker,
+			file:    "app.py",
+			source: `def run(safe):
+    name = input()
+    if safe:
+        name = "world"
+    os.system("echo " + name)
+`,
+			want: []int{5},
+		},
+		{
+			name:    "functions are analyzed on their own",
+			checker: pyChecker,
+			file:    "app.py",
+			source: `def read():
+    name = input()
+
+def run(name):
+    os.system("echo " + name)
+`,
+		},
+		{
+			name:    "fields and propagators",
+			checker: pyChecker,
+			file:    "app.py",
+			source: `class Job:
+    def start(self):
+        self.name = input()
+        args = []
+        args.append(self.name)
+        os.system(" ".join(args))
+        os.system(self.other)
+`,
+			want: []int{6},
+		},
+		{
+			name:    "loops",
+			checker: pyChecker,
+			file:    "app.py",
+			source: `def run():
+    previous = ""
+    for line in [input()]:
+        os.system(line)
+    while True:
+        os.system(previous)
+        previous = input()
+`,
+			want: []int{4, 6},
+		},
+		{
+			name: "go",
+			checker: `language: go
+name: command-injection
+message: command injection
+category: security
+severity: error
+mode: taint
+sources:
+  - '(selector_expression field: (field_identifier) @f (#eq? @f "FormValue")) @source'
+sinks:
+  - '(call_expression function: (selector_expression operand: (identifier) @pkg (#eq? @pkg "exec")) arguments: (argument_list) @sink)'
+`,
+			file: "main.go",
+			source: `package main
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("name")
+	args := []string{"-c", "echo " + name}
+	exec.Command("sh", args...)
+	exec.Command("ls")
+}
+`,
+			want: []int{6},
+		},
+		{
+			name: "javascript",
+			checker: `language: js
+name: no-eval-input
+message: eval of user input
+category: security
+severity: error
+mode: taint
+sources:
+  - '(member_expression object: (identifier) @req (#eq? @req "req")) @source'
+sinks:
+  - '(call_expression function: (identifier) @fn (#eq? @fn "eval")) @sink'
+`,
+			file: "app.js",
+			source: `function handle(req) {
+  const { code } = req.body;
+  let copy = code;
+  eval(copy);
+  copy = "1 + 1";
+  eval(copy);
+}
+`,
+			want: []int{4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ana, _, err := ReadFromBytes([]byte(tt.checker))
+			require.NoError(t, err)
+
+			lang := LanguageFromFilePath(tt.file)
+			parsed := parseTestFile(t, tt.file, tt.source, lang)
+			issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{&ana})
+			require.NoError(t, err)
+
+			var got []int
+			for _, issue := range issues {
+				got = append(got, int(issue.Range().StartPoint.Row)+1)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		ana, _, err := ReadFromBytes([]byte(pyChecker))
+		require.NoError(t, err)
+
+		parsed := parseTestFile(t, "app.py", "os.system(input())\n", LangPy)
+		issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{&ana})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "system runs a command with user input", issues[0].Message)
+	})
+}
+
+func TestInvalidYamlTaint(t *testing.T) {
+	header := "language: py\nname: taint\nmessage: taint\n"
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"no sinks", "mode: taint\nsources: [(identifier) @source]\n"},
+		{"pattern in taint mode", "mode: taint\nsources: [(identifier)]\nsinks: [(call)]\npattern: (call) @taint\n"},
+		{"fix in taint mode", "mode: taint\nsources: [(identifier)]\nsinks: [(call)]\nfix: x\n"},
+		{"sources without taint mode", "pattern: (call) @taint\nsources: [(identifier)]\n"},
+		{"unknown mode", "mode: flow\npattern: (call) @taint\n"},
+		{"propagator without captures", "mode: taint\nsources: [(identifier)]\nsinks: [(call)]\npropagators: [(call)]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadFromBytes([]byte(header + tt.yaml))
+			require.Error(t, err)
+		})
+	}
+}
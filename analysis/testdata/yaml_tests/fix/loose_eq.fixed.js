@@ -0,0 +1,4 @@
+// <expect-error>
+if (a === b) {
+  run();
+}
@@ -0,0 +1,9 @@
+import hashlib
+
+# <expect-error>
+digest = hashlib.sha256(data).hexdigest()
+
+# <expect-error>
+legacy = hashlib.sha256(b"x")
+
+strong = hashlib.sha256(data)
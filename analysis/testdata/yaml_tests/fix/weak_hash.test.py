@@ -0,0 +1,9 @@
+import hashlib
+
+# <expect-error>
+digest = hashlib.md5(data).hexdigest()
+
+# <expect-error>
+legacy = hashlib.sha1(b"x")
+
+strong = hashlib.sha256(data)
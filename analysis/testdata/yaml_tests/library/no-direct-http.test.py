@@ -0,0 +1,15 @@
+import requests
+import httpx
+import urllib.request
+
+
+def fetch(url, session):
+    # <expect-error>
+    requests.get(url)
+    # <expect-error>
+    httpx.post(url, data={})
+    # <expect-error>
+    urllib.request.urlopen(url)
+
+    session.get(url)
+    requests.Session()
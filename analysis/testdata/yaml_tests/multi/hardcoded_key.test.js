@@ -0,0 +1,3 @@
+// <expect-error>
+const apiKey = "abc123";
+const key = "abc123";
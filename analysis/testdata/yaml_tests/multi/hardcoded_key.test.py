@@ -0,0 +1,3 @@
+# <expect-error>
+API_KEY = "abc123"
+api_key = os.environ["API_KEY"]
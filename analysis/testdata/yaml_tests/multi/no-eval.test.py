@@ -0,0 +1,3 @@
+# <expect-error>
+eval(code)
+exec(code)
@@ -0,0 +1,3 @@
+eval(code)
+# <expect-error>
+exec(code)
@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io/fs"
 	"os"
@@ -10,8 +11,6 @@ import (
 	"slices"
 	"sort"
 	"strings"
-
-	sitter "github.com/smacker/go-tree-sitter"
 )
 
 func verifyIssues(expectedIssues, raisedIssues *map[string]map[int][]string) string {
@@ -124,19 +123,14 @@ func getExpectedIssuesInDir(testDir string, fileFilter func(string) bool) (map[s
 			return nil
 		}
 
-		// load the pragmas (<commentIdentifier> <expect-error>) from the test file
+		// load the pragmas (<comment> <expect-error>) from the test file
 		file, err := ParseFile(path)
 		if err != nil {
 			// skip the file if it can't be parsed
 			return nil
 		}
 
-		query, err := sitter.NewQuery([]byte("(comment) @pragma"), file.Language.Grammar())
-		if err != nil {
-			return nil
-		}
-
-		expectedIssues[path] = getExpectedIssuesInFile(file, query)
+		expectedIssues[path] = getExpectedIssuesInFile(file)
 
 		return nil
 	})
@@ -147,63 +141,39 @@ func getExpectedIssuesInDir(testDir string, fileFilter func(string) bool) (map[s
 	return expectedIssues, nil
 }
 
-func getExpectedIssuesInFile(file *ParseResult, query *sitter.Query) map[int][]string {
-	commentIdentifier := GetEscapedCommentIdentifierFromPath(file.FilePath)
+// pragmaRegexp matches <expect-error> pragmas, after the comment delimiters are removed
+var pragmaRegexp = regexp.MustCompile(`^\s*<expect-error>\s*(?P<message>.*?)\s*$`)
 
-	pattern := fmt.Sprintf(`^%s\s+<expect-error>\s*(?P<message>.*)$`, commentIdentifier)
-	pragmaRegexp := regexp.MustCompile(pattern)
+func getExpectedIssuesInFile(file *ParseResult) map[int][]string {
+	syntax := file.Language.CommentSyntax()
 
 	expectedIssues := map[int][]string{}
-	cursor := sitter.NewQueryCursor()
-	cursor.Exec(query, file.Ast)
-	for {
-		m, ok := cursor.NextMatch()
-
+	for _, comment := range commentNodes(file) {
+		text, _, commentLine := trimComment(comment, file.Source)
+		pragma, ok := syntax.CommentBody(text)
 		if !ok {
-			break
+			continue
 		}
 
-		for _, capture := range m.Captures {
-			captureName := query.CaptureNameForId(capture.Index)
-			if captureName != "pragma" {
-				continue
-			}
-			expectedLine := -1
-			pragma := capture.Node.Content(file.Source)
-			prevNode := capture.Node.PrevSibling()
-			if prevNode != nil && (prevNode.EndPoint().Row == capture.Node.StartPoint().Row) {
-				// if the comment is on the same line as the troublesome code,
-				// the line number of the issue is the same as the line number of the comment
-				expectedLine = int(prevNode.StartPoint().Row) + 1
-			} else {
-				// +2 because the pragma is on the line above the expected issue,
-				// and the line number is 0-indexed
-				expectedLine = int(capture.Node.StartPoint().Row) + 2
-			}
-			matches := pragmaRegexp.FindAllStringSubmatch(pragma, -1)
-			if matches == nil {
-				continue
-			}
-
-			message := ""
-			for _, match := range matches {
-				for i, group := range pragmaRegexp.SubexpNames() {
-					if i == 0 || group == "" {
-						continue
-					}
-
-					if group == "message" {
-						message = match[i]
-					}
-				}
-
-				if _, ok := expectedIssues[expectedLine]; !ok {
-					expectedIssues[expectedLine] = []string{}
-				}
+		matches := pragmaRegexp.FindStringSubmatch(pragma)
+		if matches == nil {
+			continue
+		}
 
-				expectedIssues[expectedLine] = append(expectedIssues[expectedLine], message)
-			}
+		expectedLine := -1
+		prevNode := comment.PrevSibling()
+		if prevNode != nil && (int(prevNode.EndPoint().Row) == commentLine) {
+			// if the comment is on the same line as the troublesome code,
+			// the line number of the issue is the same as the line number of the comment
+			expectedLine = int(prevNode.StartPoint().Row) + 1
+		} else {
+			// +2 because the pragma is on the line above the expected issue,
+			// and the line number is 0-indexed
+			expectedLine = commentLine + 2
 		}
+
+		message := matches[pragmaRegexp.SubexpIndex("message")]
+		expectedIssues[expectedLine] = append(expectedIssues[expectedLine], message)
 	}
 	return expectedIssues
 }
@@ -283,6 +253,14 @@ func RunAnalyzerTests(testDir string, analyzers []*Analyzer) (string, string, bo
 type YamlTestCase struct {
 	YamlCheckerPath string
 	TestFile        string
+	// FixedFile is the test file with the fixes of the checker applied, at
+	// <checker>.fixed.<ext>. It is empty when the file doesn't exist.
+	FixedFile string
+	// Checker is the checker under test, one of the checkers defined in the YAML file
+	Checker *Analyzer
+
+	// name identifies the test case in the test output
+	name string
 }
 
 func RunYamlTests(testDir string) (passed bool, err error) {
@@ -298,23 +276,18 @@ func RunYamlTests(testDir string) (passed bool, err error) {
 	passed = true
 	for _, test := range tests {
 		if test.TestFile == "" {
-			fmt.Fprintf(os.Stderr, "No test file found for checker '%s'\n", test.YamlCheckerPath)
+			fmt.Fprintf(os.Stderr, "No test file found for checker '%s'\n", test.name)
 			continue
 		}
 
-		fmt.Fprintf(os.Stderr, "Running test case: %s\n", filepath.Base(test.YamlCheckerPath))
-
-		checker, _, err := ReadFromFile(test.YamlCheckerPath)
-		if err != nil {
-			return false, err
-		}
+		fmt.Fprintf(os.Stderr, "Running test case: %s\n", test.name)
 
 		want, err := findExpectedLines(test.TestFile)
 		if err != nil {
 			return false, err
 		}
 
-		gotIssues, err := RunAnalyzers(test.TestFile, []*Analyzer{&checker}, nil)
+		gotIssues, err := RunAnalyzers(test.TestFile, []*Analyzer{test.Checker}, nil)
 		if err != nil {
 			return false, err
 		}
@@ -326,8 +299,16 @@ func RunYamlTests(testDir string) (passed bool, err error) {
 
 		slices.Sort(got)
 
+		if test.FixedFile != "" {
+			fixedPassed, err := checkFixedFile(test, gotIssues)
+			if err != nil {
+				return false, err
+			}
+			passed = passed && fixedPassed
+		}
+
 		if len(want) != len(got) {
-			testName := filepath.Base(test.YamlCheckerPath)
+			testName := test.name
 			message := fmt.Sprintf(
 				"(%s): expected issues on the following lines: %v\nbut issues were raised on lines: %v\n",
 				testName,
@@ -340,7 +321,7 @@ func RunYamlTests(testDir string) (passed bool, err error) {
 		}
 		for j := 0; j < len(want); j++ {
 			if want[j] != got[j] {
-				testName := filepath.Base(test.YamlCheckerPath)
+				testName := test.name
 				message := fmt.Sprintf(
 					"(%s): expected issue on line %d, but next occurrence is on line %d\n",
 					testName,
@@ -357,15 +338,80 @@ func RunYamlTests(testDir string) (passed bool, err error) {
 	return passed, nil
 }
 
+// checkFixedFile reports whether applying the fixes of issues to the test file
+// gives the content of the fixed file.
+func checkFixedFile(test YamlTestCase, issues []*Issue) (bool, error) {
+	source, err := os.ReadFile(test.TestFile)
+	if err != nil {
+		return false, err
+	}
+
+	want, err := os.ReadFile(test.FixedFile)
+	if err != nil {
+		return false, err
+	}
+
+	got, err := applyFirstFixes(source, issues)
+	if err != nil {
+		return false, fmt.Errorf("failed to apply the fixes of %s: %w", test.name, err)
+	}
+
+	if !bytes.Equal(want, got) {
+		fmt.Fprintf(os.Stderr, "(%s): fixed test file doesn't match %s, got:\n%s\n",
+			test.name, filepath.Base(test.FixedFile), got)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// applyFirstFixes applies the first fix of each issue to source, skipping the
+// fixes that overlap with the fix of an issue before them.
+func applyFirstFixes(source []byte, issues []*Issue) ([]byte, error) {
+	var fixes []SuggestedFix
+	for _, issue := range issues {
+		if len(issue.Fixes) > 0 && len(issue.Fixes[0].Edits) > 0 {
+			fixes = append(fixes, issue.Fixes[0])
+		}
+	}
+
+	slices.SortStableFunc(fixes, func(a, b SuggestedFix) int {
+		return int(a.Edits[0].StartByte) - int(b.Edits[0].StartByte)
+	})
+
+	var edits []TextEdit
+	var applied []SuggestedFix
+	for _, fix := range fixes {
+		overlaps := slices.ContainsFunc(applied, func(other SuggestedFix) bool {
+			return FixesOverlap(fix, other)
+		})
+		if !overlaps {
+			applied = append(applied, fix)
+			edits = append(edits, fix.Edits...)
+		}
+	}
+
+	return ApplyEdits(source, edits)
+}
+
 func FindYamlTestFiles(testDir string) ([]YamlTestCase, error) {
 	var pairs []YamlTestCase
 
-	err := filepath.Walk(testDir, func(path string, info fs.FileInfo, err error) error {
+	lib, err := LoadLibrary(os.DirFS(testDir), LibraryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(testDir, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
 		if info.IsDir() {
+			// the fragments in the library are not checkers
+			if path == filepath.Join(testDir, LibraryDir) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -379,25 +425,70 @@ func FindYamlTestFiles(testDir string) ([]YamlTestCase, error) {
 			return nil
 		}
 
-		patternChecker, _, err := ReadFromFile(path)
+		fileContent, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		checkers, err := lib.ReadAllFromBytes(fileContent)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "invalid checker '%s': %s\n", filepath.Base(path), err.Error())
 			return nil
 		}
 
-		testFile := strings.TrimSuffix(path, fileExt) + ".test" + GetExtFromLanguage(patternChecker.Language)
+		for i := range checkers {
+			checker := &checkers[i]
+			base := YamlTestFileBase(path, checkers, checker)
 
-		if _, err := os.Stat(testFile); os.IsNotExist(err) {
-			testFile = ""
-		}
+			testFile := base + ".test" + GetExtFromLanguage(checker.Language)
+			if _, err := os.Stat(testFile); os.IsNotExist(err) {
+				testFile = ""
+			}
+
+			fixedFile := base + ".fixed" + GetExtFromLanguage(checker.Language)
+			if _, err := os.Stat(fixedFile); os.IsNotExist(err) {
+				fixedFile = ""
+			}
 
-		pairs = append(pairs, YamlTestCase{YamlCheckerPath: path, TestFile: testFile})
+			name := filepath.Base(path)
+			if len(checkers) > 1 {
+				name = fmt.Sprintf("%s: %s (%s)", name, checker.Name, checker.Language)
+			}
+
+			pairs = append(pairs, YamlTestCase{
+				YamlCheckerPath: path,
+				TestFile:        testFile,
+				FixedFile:       fixedFile,
+				Checker:         checker,
+				name:            name,
+			})
+		}
 		return nil
 	})
 
 	return pairs, err
 }
 
+// YamlTestFileBase returns the path of the test files of a checker defined in the YAML
+// file at checkerPath with checkers, without the extension. Test files are named
+// after the YAML file, like <file>.test.<ext>, unless the file defines more than one
+// checker in the language of the checker. Then they are named after the checker,
+// like <checker>.test.<ext> next to the YAML file. The separators of checkerPath are
+// kept, so it can be the path of a file in an fs.FS.
+func YamlTestFileBase(checkerPath string, checkers []Analyzer, checker *Analyzer) string {
+	sameLanguage := 0
+	for _, other := range checkers {
+		if other.Language == checker.Language {
+			sameLanguage++
+		}
+	}
+
+	if sameLanguage > 1 {
+		return strings.TrimSuffix(checkerPath, filepath.Base(checkerPath)) + checker.Name
+	}
+	return strings.TrimSuffix(checkerPath, filepath.Ext(checkerPath))
+}
+
 func findExpectedLines(filePath string) ([]int, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
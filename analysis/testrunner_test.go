@@ -3,8 +3,8 @@ package analysis
 import (
 	"testing"
 
-	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVerifyIssues(t *testing.T) {
@@ -170,6 +170,48 @@ func TestGetExpectedIssuesInFile(t *testing.T) {
 			},
 			language: LangJs,
 		},
+		{
+			name: "block-comments.go",
+			content: `package main
+
+func main() {
+	/* <expect-error> TEST001: Block comment */
+	x := 1 == 1
+	y := 2 == 2 /* <expect-error> TEST002: Trailing block comment */
+}`,
+			want: map[int][]string{
+				5: {"TEST001: Block comment"},
+				6: {"TEST002: Trailing block comment"},
+			},
+			language: LangGo,
+		},
+		{
+			name: "line-comment-node.rs",
+			content: `fn main() {
+    // <expect-error> TEST001: Rust comment
+    let x = 1 == 1;
+}`,
+			want: map[int][]string{
+				3: {"TEST001: Rust comment"},
+			},
+			language: LangRust,
+		},
+		{
+			name: "elm-comments.elm",
+			content: `module Main exposing (..)
+
+{- <expect-error> TEST001: Elm block comment -}
+x = 1
+
+-- <expect-error> TEST002: Elm line comment
+y = 2
+`,
+			want: map[int][]string{
+				4: {"TEST001: Elm block comment"},
+				7: {"TEST002: Elm line comment"},
+			},
+			language: LangElm,
+		},
 	}
 
 	for _, tt := range tests {
@@ -179,12 +221,7 @@ func TestGetExpectedIssuesInFile(t *testing.T) {
 				t.Fatalf("Failed to parse file: %v", err)
 			}
 
-			query, err := sitter.NewQuery([]byte("(comment) @pragma"), file.Language.Grammar())
-			if err != nil {
-				t.Fatalf("Failed to create query: %v", err)
-			}
-
-			got := getExpectedIssuesInFile(file, query)
+			got := getExpectedIssuesInFile(file)
 			if !mapsEqual(got, tt.want) {
 				t.Errorf("getExpectedIssuesInFile() = %v, want %v", got, tt.want)
 			}
@@ -228,3 +265,51 @@ func mapsEqual(got, want map[int][]string) bool {
 	}
 	return true
 }
+
+func TestRunYamlTestsFixed(t *testing.T) {
+	tests, err := FindYamlTestFiles("testdata/yaml_tests/fix")
+	require.NoError(t, err)
+	for _, test := range tests {
+		assert.NotEmpty(t, test.FixedFile, test.YamlCheckerPath)
+	}
+
+	passed, err := RunYamlTests("testdata/yaml_tests/fix")
+	assert.NoError(t, err)
+	assert.True(t, passed)
+
+	passed, err = RunYamlTests("testdata/yaml_tests/fix_fail")
+	assert.NoError(t, err)
+	assert.False(t, passed, "the fixed file doesn't match")
+}
+
+func TestRunYamlTestsMultipleCheckers(t *testing.T) {
+	tests, err := FindYamlTestFiles("testdata/yaml_tests/multi")
+	require.NoError(t, err)
+
+	testFiles := map[string]string{}
+	for _, test := range tests {
+		testFiles[test.Checker.Name+"/"+test.Checker.Language.String()] = test.TestFile
+	}
+	assert.Equal(t, map[string]string{
+		"no-eval/python":           "testdata/yaml_tests/multi/no-eval.test.py",
+		"no-exec/python":           "testdata/yaml_tests/multi/no-exec.test.py",
+		"hardcoded-key/python":     "testdata/yaml_tests/multi/hardcoded_key.test.py",
+		"hardcoded-key/javascript": "testdata/yaml_tests/multi/hardcoded_key.test.js",
+	}, testFiles)
+
+	passed, err := RunYamlTests("testdata/yaml_tests/multi")
+	assert.NoError(t, err)
+	assert.True(t, passed)
+}
+
+func TestYamlTestFileBase(t *testing.T) {
+	eval := Analyzer{Name: "no-eval", Language: LangPy}
+	exec := Analyzer{Name: "no-exec", Language: LangPy}
+	key := Analyzer{Name: "hardcoded-key", Language: LangJs}
+	checkers := []Analyzer{eval, exec, key}
+
+	// the paths of an fs.FS always use '/'
+	assert.Equal(t, "python/security/no-eval", YamlTestFileBase("python/security/eval.yml", checkers, &eval))
+	assert.Equal(t, "python/security/eval", YamlTestFileBase("python/security/eval.yml", checkers, &key))
+	assert.Equal(t, "no-exec", YamlTestFileBase("eval.yml", checkers, &exec))
+}
@@ -0,0 +1,230 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"gopkg.in/yaml.v3"
+)
+
+// stringList is a list of strings in YAML, which can also be written as a single string.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = stringList{value.Value}
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// whereYaml is the set of constraints on a capture in the `where` block of a YAML checker.
+type whereYaml struct {
+	Regex    string     `yaml:"regex,omitempty"`
+	NotRegex string     `yaml:"not-regex,omitempty"`
+	In       []string   `yaml:"in,omitempty"`
+	NotIn    []string   `yaml:"not-in,omitempty"`
+	Compare  string     `yaml:"compare,omitempty"`
+	Type     stringList `yaml:"type,omitempty"`
+	NotType  stringList `yaml:"not-type,omitempty"`
+	Match    *matchYaml `yaml:"match,omitempty"`
+}
+
+// CaptureConstraint restricts the nodes a capture can match in a YAML checker.
+// A match of the checker's pattern is only reported when all the nodes captured
+// with the name satisfy the constraint.
+type CaptureConstraint struct {
+	capture  string
+	regex    *regexp.Regexp
+	notRegex *regexp.Regexp
+	in       []string
+	notIn    []string
+	// compareOp is one of <, <=, >, >=, == and !=, and compareValue the number the capture is compared to
+	compareOp    string
+	compareValue float64
+	types        []string
+	notTypes     []string
+	match        *MatchNode
+}
+
+var compareRegexp = regexp.MustCompile(`^\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+
+// compileWhere compiles the `where` block of a checker. Each capture it constrains
+// must be a capture of at least one of the patterns.
+func compileWhere(where map[string]*whereYaml, patterns []*sitter.Query, lang *sitter.Language) ([]CaptureConstraint, error) {
+	names := make([]string, 0, len(where))
+	for name := range where {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var constraints []CaptureConstraint
+	for _, name := range names {
+		w := where[name]
+		// metavariables of code patterns are captures too
+		capture := strings.TrimPrefix(strings.TrimPrefix(name, "@"), "$")
+
+		if !slices.ContainsFunc(patterns, func(q *sitter.Query) bool { return queryHasCapture(q, capture) }) {
+			return nil, fmt.Errorf("where: @%s is not a capture in the pattern", capture)
+		}
+
+		if w == nil {
+			return nil, fmt.Errorf("where: no constraint for @%s", capture)
+		}
+
+		constraint := CaptureConstraint{
+			capture:  capture,
+			in:       w.In,
+			notIn:    w.NotIn,
+			types:    w.Type,
+			notTypes: w.NotType,
+		}
+
+		var err error
+		if w.Regex != "" {
+			if constraint.regex, err = regexp.Compile(w.Regex); err != nil {
+				return nil, fmt.Errorf("where: invalid regex for @%s: %w", capture, err)
+			}
+		}
+
+		if w.NotRegex != "" {
+			if constraint.notRegex, err = regexp.Compile(w.NotRegex); err != nil {
+				return nil, fmt.Errorf("where: invalid not-regex for @%s: %w", capture, err)
+			}
+		}
+
+		if w.Compare != "" {
+			parts := compareRegexp.FindStringSubmatch(w.Compare)
+			if parts == nil {
+				return nil, fmt.Errorf("where: invalid comparison %q for @%s, expected an operator and a number like \"< 2048\"", w.Compare, capture)
+			}
+
+			value, ok := parseNumber(parts[2])
+			if !ok {
+				return nil, fmt.Errorf("where: invalid number %q in comparison for @%s", parts[2], capture)
+			}
+			constraint.compareOp, constraint.compareValue = parts[1], value
+		}
+
+		if w.Match != nil {
+			if constraint.match, err = compileMatch(w.Match, capture, lang); err != nil {
+				return nil, fmt.Errorf("where: invalid match for @%s: %w", capture, err)
+			}
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	return constraints, nil
+}
+
+// satisfied reports whether node satisfies the constraint.
+func (c *CaptureConstraint) satisfied(node *sitter.Node, source []byte) bool {
+	text := node.Content(source)
+
+	if c.regex != nil && !c.regex.MatchString(text) {
+		return false
+	}
+
+	if c.notRegex != nil && c.notRegex.MatchString(text) {
+		return false
+	}
+
+	if len(c.in) > 0 && !slices.Contains(c.in, text) {
+		return false
+	}
+
+	if slices.Contains(c.notIn, text) {
+		return false
+	}
+
+	if len(c.types) > 0 && !slices.Contains(c.types, node.Type()) {
+		return false
+	}
+
+	if slices.Contains(c.notTypes, node.Type()) {
+		return false
+	}
+
+	if c.compareOp != "" {
+		value, ok := parseNumber(text)
+		if !ok || !compareNumbers(value, c.compareOp, c.compareValue) {
+			return false
+		}
+	}
+
+	if c.match != nil && !c.match.Matches(node, source) {
+		return false
+	}
+
+	return true
+}
+
+// whereSatisfied reports whether the captures of a match satisfy all constraints.
+// The constraints on captures that query doesn't have don't apply to its matches,
+// but a match without a capture of query that is constrained doesn't satisfy it.
+func whereSatisfied(constraints []CaptureConstraint, query *sitter.Query, captures []sitter.QueryCapture, source []byte) bool {
+	for i := range constraints {
+		if !queryHasCapture(query, constraints[i].capture) {
+			continue
+		}
+
+		found := false
+		for _, capture := range captures {
+			if query.CaptureNameForId(capture.Index) != constraints[i].capture {
+				continue
+			}
+
+			found = true
+			if !constraints[i].satisfied(capture.Node, source) {
+				return false
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseNumber parses the text of a number literal, like 2048, 0x800, 1_000 or 2.5.
+func parseNumber(text string) (float64, bool) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "_", "")
+	if i, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return float64(i), true
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	return f, err == nil
+}
+
+func compareNumbers(a float64, op string, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	}
+	return false
+}
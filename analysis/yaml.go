@@ -1,8 +1,12 @@
 package analysis
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/gobwas/glob"
@@ -27,6 +31,39 @@ type filterYaml struct {
 	PatternNotInside string `yaml:"pattern-not-inside,omitempty"`
 }
 
+// fixYaml is the fix of a checker. It is either a template string, or
+// a mapping with the template in "replace".
+type fixYaml struct {
+	// Replace is nil when the mapping has no "replace", and empty to delete the target
+	Replace     *string `yaml:"replace"`
+	Target      string  `yaml:"target,omitempty"`
+	Description string  `yaml:"description,omitempty"`
+
+	// unknownKeys are the keys of the mapping that aren't fields of a fix,
+	// which are rejected by FromYaml
+	unknownKeys []string
+}
+
+func (f *fixYaml) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		f.Replace = &value.Value
+		return nil
+	}
+
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i < len(value.Content); i += 2 {
+			switch key := value.Content[i].Value; key {
+			case "replace", "target", "description":
+			default:
+				f.unknownKeys = append(f.unknownKeys, key)
+			}
+		}
+	}
+
+	type plainFix fixYaml
+	return value.Decode((*plainFix)(f))
+}
+
 type pathFilterYaml struct {
 	Exclude []string `yaml:"exclude,omitempty"`
 	Include []string `yaml:"include,omitempty"`
@@ -48,7 +85,9 @@ type PathFilter struct {
 }
 
 type Yaml struct {
-	Language    string          `yaml:"language"`
+	Language string `yaml:"language"`
+	// Languages are the languages of a checker that applies to more than one language
+	Languages   []string        `yaml:"languages,omitempty"`
 	Code        string          `yaml:"name"`
 	Message     string          `yaml:"message"`
 	Category    Category        `yaml:"category"`
@@ -60,6 +99,95 @@ type Yaml struct {
 	Include     []string        `yaml:"include,omitempty"`
 	Filters     []filterYaml    `yaml:"filters,omitempty"`
 	PathFilter  *pathFilterYaml `yaml:"path_filter,omitempty"`
+	Fix         *fixYaml        `yaml:"fix,omitempty"`
+	Match       *matchYaml      `yaml:"match,omitempty"`
+	// CodePattern is a pattern written as code in the language of the checker,
+	// with $X metavariables and ... ellipses. See CompileCodePattern.
+	CodePattern string `yaml:"code-pattern,omitempty"`
+	// Where has the constraints on the captures of the pattern, by capture name
+	Where map[string]*whereYaml `yaml:"where,omitempty"`
+	// Focus are the captures reported instead of the capture with the checker's name
+	Focus       stringList  `yaml:"focus,omitempty"`
+	ReportRange ReportRange `yaml:"report-range,omitempty"`
+	Metadata    Metadata    `yaml:"metadata,omitempty"`
+	// FileRequires and FileExcludes are match clauses on the whole file: the checker
+	// only runs on files where every clause in FileRequires matches and none in
+	// FileExcludes does
+	FileRequires matchList `yaml:"file-requires,omitempty"`
+	FileExcludes matchList `yaml:"file-excludes,omitempty"`
+	// ReportIfAbsent reports files where the pattern has no match, instead of the matches
+	ReportIfAbsent bool `yaml:"report-if-absent,omitempty"`
+	// Mode is "taint" for checkers that report the flows of data from Sources to
+	// Sinks, instead of the matches of a pattern
+	Mode        Mode       `yaml:"mode,omitempty"`
+	Sources     stringList `yaml:"sources,omitempty"`
+	Sinks       stringList `yaml:"sinks,omitempty"`
+	Sanitizers  stringList `yaml:"sanitizers,omitempty"`
+	Propagators stringList `yaml:"propagators,omitempty"`
+
+	// LanguagePatterns and LanguageCodePatterns are the patterns of a checker with
+	// several languages, by language, when `pattern` or `code-pattern` is a mapping
+	LanguagePatterns     map[string][]string `yaml:"-"`
+	LanguageCodePatterns map[string]string   `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a checker, where `pattern` and `code-pattern` can be mappings
+// from languages to the pattern in that language. A pattern in the mapping can be a
+// list of patterns.
+func (y *Yaml) UnmarshalYAML(value *yaml.Node) error {
+	type plainYaml Yaml
+	if value.Kind != yaml.MappingNode {
+		return value.Decode((*plainYaml)(y))
+	}
+
+	var patterns map[string]stringList
+	var codePatterns map[string]string
+	rest := *value
+	rest.Content = nil
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+		if val.Kind == yaml.MappingNode {
+			switch key.Value {
+			case "pattern":
+				if err := val.Decode(&patterns); err != nil {
+					return err
+				}
+				continue
+			case "code-pattern":
+				if err := val.Decode(&codePatterns); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		rest.Content = append(rest.Content, key, val)
+	}
+
+	var plain plainYaml
+	if err := rest.Decode(&plain); err != nil {
+		return err
+	}
+
+	for lang, p := range patterns {
+		if plain.LanguagePatterns == nil {
+			plain.LanguagePatterns = make(map[string][]string)
+		}
+		plain.LanguagePatterns[lang] = p
+	}
+	plain.LanguageCodePatterns = codePatterns
+
+	*y = Yaml(plain)
+	return nil
+}
+
+// FixTemplate is the fix suggested by a YAML checker. "@capture" in Replace and
+// Description is replaced with the source of the capture in the match.
+type FixTemplate struct {
+	// Replace is the code that replaces the target
+	Replace string
+	// Target is the name of the capture that is replaced. Defaults to the reported capture.
+	Target      string
+	Description string
 }
 
 type YamlAnalyzer struct {
@@ -68,6 +196,27 @@ type YamlAnalyzer struct {
 	NodeFilter []NodeFilter
 	PathFilter *PathFilter
 	Message    string
+	// (optional) Fix is the fix suggested for the issues
+	Fix *FixTemplate
+	// (optional) Match is the match tree the reported nodes must satisfy
+	Match *MatchNode
+	// (optional) Where are the constraints the captures of a match must satisfy
+	Where []CaptureConstraint
+	// (optional) Focus are the captures that are reported, the first one in a match
+	// wins. The capture with the checker's name is reported when there is none.
+	Focus []string
+	// ReportRange is the part of the reported node the issue covers
+	ReportRange ReportRange
+	// (optional) FileRequires must all match somewhere in a file, and FileExcludes
+	// must not, for the checker to run on it
+	FileRequires []*MatchNode
+	FileExcludes []*MatchNode
+	// ReportIfAbsent reports an issue at the start of the files where the patterns
+	// have no match, instead of reporting the matches
+	ReportIfAbsent bool
+	// (optional) Taint are the patterns of a checker in taint mode, which reports
+	// the sinks that tainted data reaches instead of the matches of Patterns
+	Taint *TaintPatterns
 }
 
 // ReadFromFile reads a pattern checker definition from a YAML config file.
@@ -80,20 +229,255 @@ func ReadFromFile(filePath string) (Analyzer, YamlAnalyzer, error) {
 	return ReadFromBytes(fileContent)
 }
 
-// ReadFromBytes reads a pattern checker definition from bytes array
+// ReadFromBytes reads a pattern checker definition from bytes array.
+// The definition must be of a single checker in a single language,
+// use ReadAllFromBytes to read files that define more checkers.
 func ReadFromBytes(fileContent []byte) (Analyzer, YamlAnalyzer, error) {
-	var checker Yaml
-	if err := yaml.Unmarshal(fileContent, &checker); err != nil {
+	return readFromBytes(fileContent, nil)
+}
+
+func readFromBytes(fileContent []byte, lib *Library) (Analyzer, YamlAnalyzer, error) {
+	checkers, err := decodeYamlCheckers(fileContent, lib)
+	if err != nil {
 		return Analyzer{}, YamlAnalyzer{}, err
 	}
 
+	if len(checkers) != 1 {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("expected a single checker, found %d", len(checkers))
+	}
+
+	return FromYaml(checkers[0])
+}
+
+// ReadAllFromFile reads all the checkers defined in a YAML file.
+func ReadAllFromFile(filePath string) ([]Analyzer, error) {
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadAllFromBytes(fileContent)
+}
+
+// ReadAllFromBytes reads all the checkers defined in YAML, which can have several
+// documents separated by "---". A checker with several languages is read as one
+// checker per language, all with the same name.
+func ReadAllFromBytes(fileContent []byte) ([]Analyzer, error) {
+	return readAllFromBytes(fileContent, nil)
+}
+
+func readAllFromBytes(fileContent []byte, lib *Library) ([]Analyzer, error) {
+	definitions, err := decodeYamlCheckers(fileContent, lib)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkers []Analyzer
+	for _, definition := range definitions {
+		checker, _, err := FromYaml(definition)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, checker)
+	}
+
+	return checkers, nil
+}
+
+// DecodeYamlCheckers decodes the checker definitions in YAML, with one definition per
+// language of each checker. Each definition has a single language and its patterns.
+// Checkers that use fragments are decoded with Library.DecodeYamlCheckers.
+func DecodeYamlCheckers(fileContent []byte) ([]Yaml, error) {
+	return decodeYamlCheckers(fileContent, nil)
+}
+
+func decodeYamlCheckers(fileContent []byte, lib *Library) ([]Yaml, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(fileContent))
+
+	var checkers []Yaml
+	seen := make(map[string]bool)
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		// an empty document, like the one after a trailing "---"
+		if len(document.Content) == 0 || document.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		if err := lib.resolve(&document); err != nil {
+			return nil, err
+		}
+
+		var checker Yaml
+		if err := document.Decode(&checker); err != nil {
+			return nil, err
+		}
+
+		definitions, err := checker.byLanguage()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, definition := range definitions {
+			key := definition.Code + "/" + DecodeLanguage(definition.Language).String()
+			if seen[key] {
+				return nil, fmt.Errorf("checker '%s' is defined more than once for %s", definition.Code, definition.Language)
+			}
+			seen[key] = true
+		}
+
+		checkers = append(checkers, definitions...)
+	}
+
+	if len(checkers) == 0 {
+		return nil, fmt.Errorf("no checker definition found")
+	}
+
+	return checkers, nil
+}
+
+// byLanguage splits a checker with several languages into one definition per language,
+// with the pattern of the language from the per-language patterns.
+func (checker Yaml) byLanguage() ([]Yaml, error) {
+	languages := checker.Languages
+	if len(languages) == 0 {
+		if checker.Language == "" && (checker.LanguagePatterns != nil || checker.LanguageCodePatterns != nil) {
+			return nil, fmt.Errorf("checker '%s' has patterns by language, but no 'languages'", checker.Code)
+		}
+		languages = []string{checker.Language}
+	} else if checker.Language != "" {
+		return nil, fmt.Errorf("only one of 'language' or 'languages' can be provided in checker '%s'", checker.Code)
+	}
+
+	decoded := make(map[Language]string, len(languages))
+	for _, name := range languages {
+		lang := DecodeLanguage(name)
+		if lang == LangUnknown {
+			return nil, fmt.Errorf("unknown language code: %v", name)
+		}
+		if _, ok := decoded[lang]; ok {
+			return nil, fmt.Errorf("language '%s' is listed more than once in checker '%s'", name, checker.Code)
+		}
+		decoded[lang] = name
+	}
+
+	patterns := make(map[Language][]string, len(checker.LanguagePatterns))
+	for name, p := range checker.LanguagePatterns {
+		lang := DecodeLanguage(name)
+		if _, ok := decoded[lang]; !ok {
+			return nil, fmt.Errorf("pattern for '%s' in checker '%s', which is not one of its languages", name, checker.Code)
+		}
+		patterns[lang] = p
+	}
+
+	codePatterns := make(map[Language]string, len(checker.LanguageCodePatterns))
+	for name, p := range checker.LanguageCodePatterns {
+		lang := DecodeLanguage(name)
+		if _, ok := decoded[lang]; !ok {
+			return nil, fmt.Errorf("code-pattern for '%s' in checker '%s', which is not one of its languages", name, checker.Code)
+		}
+		codePatterns[lang] = p
+	}
+
+	var definitions []Yaml
+	for _, name := range languages {
+		lang := DecodeLanguage(name)
+
+		definition := checker
+		definition.Language = name
+		definition.Languages = nil
+		definition.LanguagePatterns = nil
+		definition.LanguageCodePatterns = nil
+
+		if p, ok := patterns[lang]; ok {
+			if len(p) == 1 {
+				definition.Pattern = p[0]
+			} else {
+				definition.Patterns = p
+			}
+		}
+
+		if p, ok := codePatterns[lang]; ok {
+			definition.CodePattern = p
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, nil
+}
+
+// FromYaml builds a pattern checker from its decoded YAML definition.
+func FromYaml(checker Yaml) (Analyzer, YamlAnalyzer, error) {
 	lang, code, message, err := verifyChecker(checker)
 	if err != nil {
 		return Analyzer{}, YamlAnalyzer{}, err
 	}
 
+	mode := checker.Mode
+	if mode == "" {
+		mode = ModeSearch
+	}
+	if !mode.IsValid() {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid mode '%s' in checker '%s', expected 'search' or 'taint'", mode, code)
+	}
+
+	hasTaintPatterns := len(checker.Sources) > 0 || len(checker.Sinks) > 0 || len(checker.Sanitizers) > 0 || len(checker.Propagators) > 0
+	if mode == ModeSearch && hasTaintPatterns {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("'sources', 'sinks', 'sanitizers' and 'propagators' need 'mode: taint' in checker '%s'", code)
+	}
+
 	var patterns []*sitter.Query
-	if checker.Pattern != "" {
+	var match *MatchNode
+	var taint *TaintPatterns
+	if mode == ModeTaint {
+		if checker.Pattern != "" || len(checker.Patterns) > 0 || checker.Match != nil || checker.CodePattern != "" {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("'pattern', 'patterns', 'match' and 'code-pattern' can't be used with 'mode: taint' in checker '%s'", code)
+		}
+		if checker.Fix != nil || len(checker.Focus) > 0 || len(checker.Where) > 0 || len(checker.Filters) > 0 || checker.ReportIfAbsent {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("'fix', 'focus', 'where', 'filters' and 'report-if-absent' can't be used with 'mode: taint' in checker '%s'", code)
+		}
+
+		taint, err = compileTaint(checker, lang.Grammar())
+		if err != nil {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid checker '%s': %w", code, err)
+		}
+	} else if checker.CodePattern != "" {
+		if checker.Pattern != "" || len(checker.Patterns) > 0 || checker.Match != nil {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("'code-pattern' can't be used with 'pattern', 'patterns' or 'match' in checker '%s'", code)
+		}
+
+		queryStr, err := CompileCodePattern(checker.CodePattern, lang, code)
+		if err != nil {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid code-pattern in checker '%s': %w", code, err)
+		}
+
+		pattern, err := sitter.NewQuery([]byte(queryStr), lang.Grammar())
+		if err != nil {
+			return Analyzer{}, YamlAnalyzer{}, err
+		}
+		patterns = append(patterns, pattern)
+	} else if checker.Match != nil {
+		if checker.Pattern != "" || len(checker.Patterns) > 0 {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("'match' can't be used with 'pattern' or 'patterns' in checker '%s'", code)
+		}
+
+		match, err = compileMatch(checker.Match, code, lang.Grammar())
+		if err != nil {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid match in checker '%s': %w", code, err)
+		}
+
+		patterns = match.reportPatterns()
+		if len(patterns) == 0 {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("match in checker '%s' has no pattern with a @%s capture outside of 'not', 'inside' and 'has'", code, code)
+		}
+	} else if checker.Pattern != "" {
 		pattern, err := sitter.NewQuery([]byte(checker.Pattern), lang.Grammar())
 		if err != nil {
 			return Analyzer{}, YamlAnalyzer{}, err
@@ -172,20 +556,92 @@ func ReadFromBytes(fileContent []byte) (Analyzer, YamlAnalyzer, error) {
 		}
 	}
 
+	where, err := compileWhere(checker.Where, patterns, lang.Grammar())
+	if err != nil {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid checker '%s': %w", code, err)
+	}
+
+	var focus []string
+	for _, name := range checker.Focus {
+		focus = append(focus, strings.TrimPrefix(strings.TrimPrefix(name, "@"), "$"))
+	}
+	if err := compileFocus(focus, patterns); err != nil {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid checker '%s': %w", code, err)
+	}
+
+	reportRange := checker.ReportRange
+	if reportRange == "" {
+		reportRange = ReportRangeNode
+	}
+	if !reportRange.IsValid() {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid report-range '%s' in checker '%s', expected 'node', 'line' or 'name'", reportRange, code)
+	}
+
+	fileRequires, err := compileFileConditions(checker.FileRequires, code, lang.Grammar())
+	if err != nil {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid file-requires in checker '%s': %w", code, err)
+	}
+	fileExcludes, err := compileFileConditions(checker.FileExcludes, code, lang.Grammar())
+	if err != nil {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid file-excludes in checker '%s': %w", code, err)
+	}
+
+	if checker.ReportIfAbsent && (checker.Fix != nil || len(focus) > 0) {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("'fix' and 'focus' can't be used with 'report-if-absent' in checker '%s'", code)
+	}
+
+	var fix *FixTemplate
+	if checker.Fix != nil {
+		if len(checker.Fix.unknownKeys) > 0 {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("unknown key '%s' in the fix of checker '%s', expected 'replace', 'target' or 'description'", checker.Fix.unknownKeys[0], code)
+		}
+		// without "replace", a typo in its key would delete the target
+		if checker.Fix.Replace == nil {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("the fix of checker '%s' has no 'replace', use `replace: \"\"` to delete the target", code)
+		}
+
+		fix = &FixTemplate{
+			Replace:     *checker.Fix.Replace,
+			Target:      strings.TrimPrefix(strings.TrimPrefix(checker.Fix.Target, "@"), "$"),
+			Description: checker.Fix.Description,
+		}
+
+		// matches of the patterns without the target get no fix
+		if fix.Target != "" && !slices.ContainsFunc(patterns, func(q *sitter.Query) bool { return queryHasCapture(q, fix.Target) }) {
+			return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("fix target @%s is not a capture in the pattern of checker '%s'", fix.Target, code)
+		}
+	}
+
+	metadata := checker.Metadata
+	if err := metadata.Normalize(); err != nil {
+		return Analyzer{}, YamlAnalyzer{}, fmt.Errorf("invalid metadata in checker '%s': %w", code, err)
+	}
+
 	patternChecker := Analyzer{
 		Name:        code,
 		Language:    lang,
 		Description: checker.Description,
 		Category:    checker.Category,
 		Severity:    checker.Severity,
+		Metadata:    metadata,
 	}
 
 	yamlAnalyzer := &YamlAnalyzer{
-		Analyzer:   &patternChecker,
-		Patterns:   patterns,
-		NodeFilter: filters,
-		PathFilter: pathFilter,
-		Message:    message,
+		Analyzer:    &patternChecker,
+		Patterns:    patterns,
+		NodeFilter:  filters,
+		PathFilter:  pathFilter,
+		Message:     message,
+		Fix:         fix,
+		Match:       match,
+		Where:       where,
+		Focus:       focus,
+		ReportRange: reportRange,
+
+		FileRequires:   fileRequires,
+		FileExcludes:   fileExcludes,
+		ReportIfAbsent: checker.ReportIfAbsent,
+		Taint:          taint,
 	}
 
 	patternChecker.Run = RunYamlAnalyzer(yamlAnalyzer)
@@ -194,6 +650,18 @@ func ReadFromBytes(fileContent []byte) (Analyzer, YamlAnalyzer, error) {
 
 func RunYamlAnalyzer(YamlAnalyzer *YamlAnalyzer) func(pass *Pass) (any, error) {
 	return func(pass *Pass) (any, error) {
+		if !YamlAnalyzer.fileConditionsHold(pass.FileContext) {
+			return nil, nil
+		}
+
+		if YamlAnalyzer.Taint != nil {
+			YamlAnalyzer.runTaint(pass)
+			return nil, nil
+		}
+
+		// several patterns, or several matches of a pattern, can report the same node
+		reported := make(map[*sitter.Node]bool)
+
 		queries := YamlAnalyzer.Patterns
 		for _, query := range queries {
 			qc := sitter.NewQueryCursor()
@@ -205,26 +673,155 @@ func RunYamlAnalyzer(YamlAnalyzer *YamlAnalyzer) func(pass *Pass) (any, error) {
 					break
 				}
 				m = qc.FilterPredicates(m, pass.FileContext.Source)
+				if !whereSatisfied(YamlAnalyzer.Where, query, m.Captures, pass.FileContext.Source) {
+					continue
+				}
+
 				for _, capture := range m.Captures {
 					captureName := query.CaptureNameForId(capture.Index)
 					if captureName == pass.Analyzer.Name && YamlAnalyzer.runParentFilters(pass.FileContext.Source, capture.Node) {
-						message := YamlAnalyzer.Message
-						for _, capture := range m.Captures {
-							captureName := query.CaptureNameForId(capture.Index)
-							message = strings.ReplaceAll(message, "@"+captureName, capture.Node.Content(pass.FileContext.Source))
+						if YamlAnalyzer.Match != nil && !YamlAnalyzer.Match.Matches(capture.Node, pass.FileContext.Source) {
+							continue
+						}
+
+						node := focusNode(YamlAnalyzer.Focus, query, m.Captures, capture.Node)
+						if YamlAnalyzer.ReportRange == ReportRangeName {
+							node = nameNode(node)
+						}
+
+						if reported[node] {
+							continue
 						}
+						reported[node] = true
 
-						pass.Report(pass, capture.Node, message)
+						// the pattern is present, so there is nothing to report
+						if YamlAnalyzer.ReportIfAbsent {
+							return nil, nil
+						}
+
+						message := interpolateCaptures(YamlAnalyzer.Message, query, m.Captures, pass.FileContext.Source)
+
+						var fixes []SuggestedFix
+						if YamlAnalyzer.Fix != nil {
+							fixes = YamlAnalyzer.Fix.suggest(query, m.Captures, node, pass.FileContext.Source)
+						}
+
+						switch {
+						case YamlAnalyzer.ReportRange == ReportRangeLine && pass.ReportRange != nil:
+							pass.ReportRange(pass, node, lineRange(node, pass.FileContext.Source), message, fixes...)
+						case YamlAnalyzer.Fix != nil:
+							pass.ReportWithFixes(pass, node, message, fixes...)
+						default:
+							pass.Report(pass, node, message)
+						}
 					}
 				}
 
 			}
 		}
+
+		if YamlAnalyzer.ReportIfAbsent {
+			root := pass.FileContext.Ast
+			if pass.ReportRange != nil {
+				pass.ReportRange(pass, root, lineRange(root, pass.FileContext.Source), YamlAnalyzer.Message)
+			} else {
+				pass.Report(pass, root, YamlAnalyzer.Message)
+			}
+		}
 		return nil, nil
 	}
 
 }
 
+// compileFileConditions compiles the match clauses of file-requires or file-excludes.
+func compileFileConditions(conditions matchList, name string, lang *sitter.Language) ([]*MatchNode, error) {
+	var compiled []*MatchNode
+	for _, condition := range conditions {
+		m, err := compileMatch(condition, name, lang)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, m)
+	}
+	return compiled, nil
+}
+
+// fileConditionsHold reports whether file has a match for every clause of
+// FileRequires, and for none of FileExcludes.
+func (ana *YamlAnalyzer) fileConditionsHold(file *ParseResult) bool {
+	for _, condition := range ana.FileRequires {
+		if !condition.matchesInTree(file.Ast, file.Source) {
+			return false
+		}
+	}
+
+	for _, condition := range ana.FileExcludes {
+		if condition.matchesInTree(file.Ast, file.Source) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// interpolateCaptures replaces "@capture" in template with the source of the capture,
+// and "$X" with the source of the metavariable X of a code pattern.
+// Longer names are replaced first, so that @arg doesn't replace the start of @args.
+func interpolateCaptures(template string, query *sitter.Query, captures []sitter.QueryCapture, source []byte) string {
+	sorted := slices.Clone(captures)
+	slices.SortStableFunc(sorted, func(a, b sitter.QueryCapture) int {
+		return len(query.CaptureNameForId(b.Index)) - len(query.CaptureNameForId(a.Index))
+	})
+
+	for _, capture := range sorted {
+		captureName := query.CaptureNameForId(capture.Index)
+		template = strings.ReplaceAll(template, "@"+captureName, capture.Node.Content(source))
+		if metavariableNameRegexp.MatchString(captureName) {
+			template = strings.ReplaceAll(template, "$"+captureName, capture.Node.Content(source))
+		}
+	}
+	return template
+}
+
+// suggest returns the fix for a match of query, where reported is the node of the issue.
+// There is no fix when the match doesn't have the target capture.
+func (fix *FixTemplate) suggest(query *sitter.Query, captures []sitter.QueryCapture, reported *sitter.Node, source []byte) []SuggestedFix {
+	target := reported
+	if fix.Target != "" {
+		target = nil
+		for _, capture := range captures {
+			if query.CaptureNameForId(capture.Index) == fix.Target {
+				target = capture.Node
+				break
+			}
+		}
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	replacement := interpolateCaptures(fix.Replace, query, captures, source)
+	description := interpolateCaptures(fix.Description, query, captures, source)
+	if description == "" {
+		description = fmt.Sprintf("Replace with `%s`", replacement)
+	}
+
+	return []SuggestedFix{{
+		Description: description,
+		Edits:       []TextEdit{ReplaceNode(target, replacement)},
+	}}
+}
+
+func queryHasCapture(query *sitter.Query, name string) bool {
+	for i := uint32(0); i < query.CaptureCount(); i++ {
+		if query.CaptureNameForId(i) == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (ana *YamlAnalyzer) runParentFilters(source []byte, capture *sitter.Node) bool {
 	filters := ana.NodeFilter
 	if len(filters) == 0 {
@@ -284,7 +881,7 @@ func verifyChecker(checker Yaml) (Language, string, string, error) {
 	msg := checker.Message
 
 	if lang == LangUnknown {
-		return lang, code, msg, fmt.Errorf("unknown language code: %v", lang)
+		return lang, code, msg, fmt.Errorf("unknown language code: %v", checker.Language)
 	}
 
 	if (code == "") || (msg == "") {
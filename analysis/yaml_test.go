@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"fmt"
 	"testing"
 
 	sitter "github.com/smacker/go-tree-sitter"
@@ -370,3 +371,668 @@ func TestReadFromBytes(t *testing.T) {
 	assert.Error(t, err)
 
 }
+
+func TestYamlFix(t *testing.T) {
+	checker := `
+language: python
+name: weak-hash
+message: "Use a stronger hash than @hash"
+category: security
+severity: warning
+pattern: >
+  (call
+    function: (attribute
+      object: (identifier) @module
+      attribute: (identifier) @hash)
+    arguments: (argument_list (_) @args)
+    (#eq? @module "hashlib")) @weak-hash
+`
+
+	tests := []struct {
+		name            string
+		fix             string
+		wantErr         bool
+		wantFixed       string
+		wantDescription string
+	}{
+		{
+			name:            "template",
+			fix:             `fix: "hashlib.sha256(@args)"`,
+			wantFixed:       "x = hashlib.sha256(data)\n",
+			wantDescription: "Replace with `hashlib.sha256(data)`",
+		},
+		{
+			name:            "target capture",
+			fix:             "fix:\n  target: \"@hash\"\n  replace: sha256\n  description: Use sha256 instead of @hash",
+			wantFixed:       "x = hashlib.sha256(data)\n",
+			wantDescription: "Use sha256 instead of md5",
+		},
+		{
+			name:    "unknown target",
+			fix:     "fix:\n  target: \"@digest\"\n  replace: sha256",
+			wantErr: true,
+		},
+		{
+			name:            "empty replace deletes the target",
+			fix:             "fix:\n  target: \"@args\"\n  replace: \"\"",
+			wantFixed:       "x = hashlib.md5()\n",
+			wantDescription: "Replace with ``",
+		},
+		{
+			name:    "unknown key",
+			fix:     "fix:\n  template: sha256\n  target: \"@hash\"",
+			wantErr: true,
+		},
+		{
+			name:    "no replace",
+			fix:     "fix:\n  target: \"@args\"",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ana, _, err := ReadFromBytes([]byte(checker + tt.fix))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			source := []byte("x = hashlib.md5(data)\n")
+			parsed, err := Parse("app.py", source, LangPy, LangPy.Grammar())
+			require.NoError(t, err)
+
+			var fixes []SuggestedFix
+			pass := &Pass{
+				Analyzer:    &ana,
+				FileContext: parsed,
+				Files:       []*ParseResult{parsed},
+				ReportWithFixes: func(pass *Pass, node *sitter.Node, message string, suggested ...SuggestedFix) {
+					assert.Equal(t, "Use a stronger hash than md5", message)
+					fixes = append(fixes, suggested...)
+				},
+			}
+
+			_, err = ana.Run(pass)
+			require.NoError(t, err)
+			require.Len(t, fixes, 1)
+			assert.Equal(t, tt.wantDescription, fixes[0].Description)
+
+			fixed, err := ApplyEdits(source, fixes[0].Edits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFixed, string(fixed))
+		})
+	}
+}
+
+func TestYamlFixTargetInSomePatterns(t *testing.T) {
+	checker := `
+language: python
+name: weak-hash
+message: "Use a stronger hash"
+category: security
+severity: warning
+patterns:
+  - >
+    (call
+      function: (attribute
+        object: (identifier) @module
+        attribute: (identifier) @hash)
+      (#eq? @module "hashlib")) @weak-hash
+  - '(call function: (identifier) @fn (#eq? @fn "crc32")) @weak-hash'
+fix:
+  target: "@hash"
+  replace: sha256
+`
+	ana, _, err := ReadFromBytes([]byte(checker))
+	require.NoError(t, err)
+
+	source := []byte("x = hashlib.md5(data)\ny = crc32(data)\n")
+	parsed, err := Parse("app.py", source, LangPy, LangPy.Grammar())
+	require.NoError(t, err)
+
+	var fixes [][]SuggestedFix
+	pass := &Pass{
+		Analyzer:    &ana,
+		FileContext: parsed,
+		Files:       []*ParseResult{parsed},
+		ReportWithFixes: func(pass *Pass, node *sitter.Node, message string, suggested ...SuggestedFix) {
+			fixes = append(fixes, suggested)
+		},
+	}
+
+	_, err = ana.Run(pass)
+	require.NoError(t, err)
+	require.Len(t, fixes, 2)
+	require.Len(t, fixes[0], 1, "the match with the target is fixed")
+	assert.Empty(t, fixes[1], "the match without the target has no fix")
+
+	fixed, err := ApplyEdits(source, fixes[0][0].Edits)
+	require.NoError(t, err)
+	assert.Equal(t, "x = hashlib.sha256(data)\ny = crc32(data)\n", string(fixed))
+}
+
+func TestYamlMatch(t *testing.T) {
+	source := `
+exec(code)
+exec("print(1)")
+eval(code)
+
+def safe_run(code):
+    exec(code)
+
+def run(code):
+    exec(code)
+    exec(f"{code}")
+    exec(compile(src))
+`
+
+	tests := []struct {
+		name    string
+		match   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all, not, has and not-inside",
+			match: `
+match:
+  all:
+    - pattern: '(call function: (identifier) @fn (#eq? @fn "exec")) @exec-call'
+    - not:
+        has: (string)
+    - not-inside: '(function_definition name: (identifier) @name (#match? @name "^safe_"))'
+`,
+			want: []string{"exec(code)", "exec(code)", "exec(compile(src))"},
+		},
+		{
+			name: "any with a shorthand pattern",
+			match: `
+match:
+  any:
+    - '(call function: (identifier) @fn (#eq? @fn "exec")) @exec-call'
+    - '(call function: (identifier) @fn (#eq? @fn "eval")) @exec-call'
+`,
+			want: []string{"exec(code)", "exec(\"print(1)\")", "eval(code)", "exec(code)", "exec(code)", "exec(f\"{code}\")", "exec(compile(src))"},
+		},
+		{
+			name: "inside and not-has",
+			match: `
+match:
+  all:
+    - (call) @exec-call
+    - inside: (function_definition)
+    - not-has: (identifier) @id (#eq? @id "code")
+`,
+			want: []string{"exec(compile(src))", "compile(src)"},
+		},
+		{
+			name: "pattern without the reported capture",
+			match: `
+match:
+  not: (call) @exec-call
+`,
+			wantErr: true,
+		},
+		{
+			name: "two operators in a clause",
+			match: `
+match:
+  pattern: (call) @exec-call
+  not: (string)
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := "language: py\nname: exec-call\nmessage: exec\ncategory: security\nseverity: warning\n" + tt.match
+			ana, _, err := ReadFromBytes([]byte(checker))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parsed, err := Parse("app.py", []byte(source), LangPy, LangPy.Grammar())
+			require.NoError(t, err)
+
+			var got []string
+			pass := &Pass{
+				Analyzer:    &ana,
+				FileContext: parsed,
+				Files:       []*ParseResult{parsed},
+				Report: func(pass *Pass, node *sitter.Node, message string) {
+					got = append(got, node.Content(pass.FileContext.Source))
+				},
+			}
+
+			_, err = ana.Run(pass)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestYamlWhere(t *testing.T) {
+	source := `
+rsa.generate_private_key(public_exponent=65537, key_size=1024)
+rsa.generate_private_key(public_exponent=65537, key_size=4096)
+rsa.generate_private_key(public_exponent=65537, key_size=0x400)
+rsa.generate_private_key(public_exponent=65537, key_size=size)
+dsa.generate_private_key(key_size=1024)
+`
+
+	pattern := `
+pattern: >
+  (call
+    function: (attribute object: (identifier) @module attribute: (identifier) @fn)
+    arguments: (argument_list
+      (keyword_argument name: (identifier) @kw value: (_) @size (#eq? @kw "key_size")))) @weak-key
+`
+
+	tests := []struct {
+		name    string
+		where   string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:  "numeric comparison",
+			where: "where:\n  size:\n    compare: \"< 2048\"\n",
+			want:  []int{1, 3, 5},
+		},
+		{
+			name:  "set membership and regex",
+			where: "where:\n  \"@module\":\n    in: [rsa]\n  size:\n    regex: \"^[0-9]+$\"\n",
+			want:  []int{1, 2},
+		},
+		{
+			name:  "negated regex and set",
+			where: "where:\n  module:\n    not-regex: \"^d\"\n  size:\n    not-in: [\"4096\"]\n",
+			want:  []int{1, 3, 4},
+		},
+		{
+			name:  "node type",
+			where: "where:\n  size:\n    type: identifier\n",
+			want:  []int{4},
+		},
+		{
+			name:  "negated node types",
+			where: "where:\n  size:\n    not-type: [identifier, string]\n  module:\n    in: [rsa]\n",
+			want:  []int{1, 2, 3},
+		},
+		{
+			name:  "sub-pattern on the capture",
+			where: "where:\n  size:\n    match: '((integer) @size (#match? @size \"^0x\"))'\n",
+			want:  []int{3},
+		},
+		{
+			name:    "unknown capture",
+			where:   "where:\n  bits:\n    compare: \"< 2048\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid comparison",
+			where:   "where:\n  size:\n    compare: \"small\"\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := "language: py\nname: weak-key\nmessage: weak key\ncategory: security\nseverity: warning\n" + pattern + tt.where
+			ana, _, err := ReadFromBytes([]byte(checker))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parsed, err := Parse("app.py", []byte(source), LangPy, LangPy.Grammar())
+			require.NoError(t, err)
+
+			var got []int
+			pass := &Pass{
+				Analyzer:    &ana,
+				FileContext: parsed,
+				Files:       []*ParseResult{parsed},
+				Report: func(pass *Pass, node *sitter.Node, message string) {
+					got = append(got, int(node.StartPoint().Row))
+				},
+			}
+
+			_, err = ana.Run(pass)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestYamlMatchInsideSeveralFiles(t *testing.T) {
+	checker := `language: py
+name: exec-call
+message: exec
+category: security
+severity: warning
+match:
+  all:
+    - '(call function: (identifier) @fn (#eq? @fn "exec")) @exec-call'
+    - inside: (function_definition)
+`
+	ana, _, err := ReadFromBytes([]byte(checker))
+	require.NoError(t, err)
+
+	// the nodes matched by the inside pattern are found once per file, and
+	// must not be reused for another file
+	sources := map[string]string{
+		"a.py": "def f():\n    exec(code)\n",
+		"b.py": "exec(code)\n",
+		"c.py": "exec(code)\ndef g():\n    exec(code)\n",
+	}
+
+	var files []*ParseResult
+	for _, name := range []string{"a.py", "b.py", "c.py", "a.py"} {
+		files = append(files, parseTestFile(t, name, sources[name], LangPy))
+	}
+
+	issues, err := RunAnalyzersOnFiles("", files, []*Analyzer{&ana})
+	require.NoError(t, err)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, fmt.Sprintf("%s:%d", issue.Filepath, issue.Range().StartPoint.Row+1))
+	}
+	assert.ElementsMatch(t, []string{"a.py:2", "c.py:3", "a.py:2"}, got)
+}
+
+func TestYamlWhereSeveralPatterns(t *testing.T) {
+	checker := `language: py
+name: weak-crypto
+message: weak crypto
+category: security
+severity: warning
+patterns:
+  - >
+    (call
+      arguments: (argument_list
+        (keyword_argument name: (identifier) @kw value: (_) @size (#eq? @kw "key_size")))) @weak-crypto
+  - '(call function: (attribute attribute: (identifier) @fn (#eq? @fn "md5"))) @weak-crypto'
+where:
+  size:
+    compare: "< 2048"
+`
+	source := `
+rsa.generate_private_key(key_size=1024)
+rsa.generate_private_key(key_size=4096)
+hashlib.md5(data)
+`
+
+	ana, _, err := ReadFromBytes([]byte(checker))
+	require.NoError(t, err)
+
+	parsed, err := Parse("app.py", []byte(source), LangPy, LangPy.Grammar())
+	require.NoError(t, err)
+
+	var got []int
+	pass := &Pass{
+		Analyzer:    &ana,
+		FileContext: parsed,
+		Files:       []*ParseResult{parsed},
+		Report: func(pass *Pass, node *sitter.Node, message string) {
+			got = append(got, int(node.StartPoint().Row))
+		},
+	}
+
+	_, err = ana.Run(pass)
+	require.NoError(t, err)
+	// the constraint on @size doesn't apply to the pattern without it
+	assert.ElementsMatch(t, []int{1, 3}, got)
+}
+
+func TestYamlFocus(t *testing.T) {
+	source := `package main
+
+func client() {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true,
+	}
+	hash := md5.Sum(data)
+}
+`
+
+	pattern := `
+pattern: >
+  (composite_literal
+    body: (literal_value
+      (keyed_element
+        (literal_element (identifier) @key)
+        (literal_element (true))) @pair
+      (#eq? @key "InsecureSkipVerify"))) @tls-insecure
+`
+
+	tests := []struct {
+		name    string
+		options string
+		want    sitter.Range
+		wantErr bool
+	}{
+		{
+			name: "whole node",
+			want: sitter.Range{StartPoint: sitter.Point{Row: 3, Column: 12}, EndPoint: sitter.Point{Row: 6, Column: 2}},
+		},
+		{
+			name:    "focus on a capture",
+			options: "focus: \"@pair\"\n",
+			want:    sitter.Range{StartPoint: sitter.Point{Row: 5, Column: 2}, EndPoint: sitter.Point{Row: 5, Column: 26}},
+		},
+		{
+			name:    "unknown focus capture",
+			options: "focus: [missing, key]\n",
+			wantErr: true,
+		},
+		{
+			name:    "focus list",
+			options: "focus: [key, pair]\n",
+			want:    sitter.Range{StartPoint: sitter.Point{Row: 5, Column: 2}, EndPoint: sitter.Point{Row: 5, Column: 20}},
+		},
+		{
+			name:    "first line",
+			options: "report-range: line\n",
+			want:    sitter.Range{StartPoint: sitter.Point{Row: 3, Column: 1}, EndPoint: sitter.Point{Row: 3, Column: 23}},
+		},
+		{
+			name:    "invalid report range",
+			options: "report-range: token\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := "language: go\nname: tls-insecure\nmessage: insecure\ncategory: security\nseverity: warning\n" + pattern + tt.options
+			ana, _, err := ReadFromBytes([]byte(checker))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parsed := parseTestFile(t, "main.go", source, LangGo)
+			issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{&ana})
+			require.NoError(t, err)
+			require.Len(t, issues, 1)
+
+			got := issues[0].Range()
+			assert.Equal(t, tt.want.StartPoint, got.StartPoint)
+			assert.Equal(t, tt.want.EndPoint, got.EndPoint)
+		})
+	}
+
+	t.Run("name of a call", func(t *testing.T) {
+		checker := "language: go\nname: weak-hash\nmessage: weak hash\ncategory: security\nseverity: warning\n" +
+			"code-pattern: md5.Sum($X)\nreport-range: name\n"
+		ana, _, err := ReadFromBytes([]byte(checker))
+		require.NoError(t, err)
+
+		parsed := parseTestFile(t, "main.go", source, LangGo)
+		issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{&ana})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "Sum", issues[0].Node.Content(parsed.Source))
+	})
+}
+
+func TestReadAllFromBytes(t *testing.T) {
+	header := "name: hardcoded-key\nmessage: hardcoded key\ncategory: security\nseverity: warning\n"
+
+	tests := []struct {
+		name    string
+		yaml    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "patterns by language",
+			yaml: header + "languages: [py, js]\npattern:\n  py: (string) @hardcoded-key\n  js: [\"(string) @hardcoded-key\", \"(template_string) @hardcoded-key\"]\n",
+			want: []string{"hardcoded-key/python", "hardcoded-key/javascript"},
+		},
+		{
+			name: "shared code pattern",
+			yaml: header + "languages: [py, js]\ncode-pattern: setKey(\"...\")\n",
+			want: []string{"hardcoded-key/python", "hardcoded-key/javascript"},
+		},
+		{
+			name: "several documents",
+			yaml: "---\nlanguage: py\n" + header + "pattern: (string) @hardcoded-key\n---\nlanguage: py\nname: other\nmessage: other\npattern: (integer) @other\n---\n",
+			want: []string{"hardcoded-key/python", "other/python"},
+		},
+		{
+			name:    "pattern for a language that isn't listed",
+			yaml:    header + "languages: [py]\npattern:\n  go: (interpreted_string_literal) @hardcoded-key\n",
+			wantErr: true,
+		},
+		{
+			name:    "no pattern for a language",
+			yaml:    header + "languages: [py, js]\npattern:\n  py: (string) @hardcoded-key\n",
+			wantErr: true,
+		},
+		{
+			name:    "both language and languages",
+			yaml:    header + "language: py\nlanguages: [js]\npattern: (string) @hardcoded-key\n",
+			wantErr: true,
+		},
+		{
+			name:    "same checker twice",
+			yaml:    "language: py\n" + header + "pattern: (string) @hardcoded-key\n---\nlanguage: python\n" + header + "pattern: (string) @hardcoded-key\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkers, err := ReadAllFromBytes([]byte(tt.yaml))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, checker := range checkers {
+				got = append(got, checker.Name+"/"+checker.Language.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// ReadFromBytes only reads files with a single checker
+	_, _, err := ReadFromBytes([]byte(header + "languages: [py, js]\ncode-pattern: setKey(\"...\")\n"))
+	assert.Error(t, err)
+}
+
+func TestYamlFileConditions(t *testing.T) {
+	flaskApp := "from flask import Flask\n\napp = Flask(__name__)\napp.run(debug=True)\n"
+	otherApp := "import server\n\nserver.app.run(debug=True)\n"
+	settings := "DEBUG = False\nSECURE_SSL_REDIRECT = True\n"
+
+	runPattern := "pattern: '(call function: (attribute attribute: (identifier) @fn (#eq? @fn \"run\"))) @flask-run'\n"
+	importsFlask := "'(import_from_statement module_name: (dotted_name) @module (#eq? @module \"flask\"))'"
+
+	tests := []struct {
+		name    string
+		options string
+		source  string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "required pattern present",
+			options: runPattern + "file-requires:\n  - " + importsFlask + "\n",
+			source:  flaskApp,
+			want:    []int{4},
+		},
+		{
+			name:    "required pattern missing",
+			options: runPattern + "file-requires: " + importsFlask + "\n",
+			source:  otherApp,
+		},
+		{
+			name:    "excluded pattern present",
+			options: runPattern + "file-excludes:\n  - not-has: (import_from_statement)\n  - '(comment) @c (#match? @c \"nosec\")'\n",
+			source:  otherApp,
+		},
+		{
+			name:    "excluded pattern missing",
+			options: runPattern + "file-excludes:\n  - \"(comment)\"\n",
+			source:  otherApp,
+			want:    []int{3},
+		},
+		{
+			name:    "report if absent",
+			options: "report-if-absent: true\npattern: '(assignment left: (identifier) @name (#eq? @name \"SECURE_HSTS_SECONDS\")) @flask-run'\n",
+			source:  settings,
+			want:    []int{1},
+		},
+		{
+			name:    "present when reported if absent",
+			options: "report-if-absent: true\npattern: '(assignment left: (identifier) @name (#eq? @name \"DEBUG\")) @flask-run'\n",
+			source:  settings,
+		},
+		{
+			name:    "fix when reported if absent",
+			options: "report-if-absent: true\n" + runPattern + "fix: \"app.run()\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid file condition",
+			options: runPattern + "file-requires:\n  - any: []\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := "language: py\nname: flask-run\nmessage: flask app\ncategory: security\nseverity: warning\n" + tt.options
+			ana, _, err := ReadFromBytes([]byte(checker))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parsed := parseTestFile(t, "app.py", tt.source, LangPy)
+			issues, err := RunAnalyzersOnFiles("", []*ParseResult{parsed}, []*Analyzer{&ana})
+			require.NoError(t, err)
+
+			var got []int
+			for _, issue := range issues {
+				got = append(got, int(issue.Range().StartPoint.Row)+1)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -6,20 +6,28 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"globstar.dev/analysis"
 )
 
-//go:embed **/*.y*ml
+// builtinCheckers holds the YAML checkers along with the test files of
+// all built-in checkers, which are used as examples by `globstar desc`.
+//
+//go:embed **/*.y*ml */*.test.* */testdata/*.test.*
 var builtinCheckers embed.FS
 
-func findYamlCheckers(checkersMap map[analysis.Language][]analysis.Analyzer, readFile func(string) ([]byte, error)) func(path string, d fs.DirEntry, err error) error {
+func findYamlCheckers(checkersMap map[analysis.Language][]analysis.Analyzer, lib *analysis.Library, readFile func(string) ([]byte, error)) func(path string, d fs.DirEntry, err error) error {
 	return func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 
 		if d.IsDir() {
+			// the library has the fragments used by the checkers, which are not checkers
+			if path == analysis.LibraryDir {
+				return fs.SkipDir
+			}
 			return nil
 		}
 
@@ -29,31 +37,48 @@ func findYamlCheckers(checkersMap map[analysis.Language][]analysis.Analyzer, rea
 			return nil
 		}
 
+		// hidden files like .config.yml are not checkers
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
 		fileContent, err := readFile(path)
 		if err != nil {
 			return nil
 		}
 
-		patternChecker, _, err := analysis.ReadFromBytes(fileContent)
+		patternCheckers, err := lib.ReadAllFromBytes(fileContent)
 		if err != nil {
 			return fmt.Errorf("invalid checker '%s': %s", d.Name(), err.Error())
 		}
 
-		lang := patternChecker.Language
-		checkersMap[lang] = append(checkersMap[lang], patternChecker)
+		for _, patternChecker := range patternCheckers {
+			lang := patternChecker.Language
+			checkersMap[lang] = append(checkersMap[lang], patternChecker)
+		}
 		return nil
 	}
 }
 
 func LoadBuiltinYamlCheckers() (map[analysis.Language][]analysis.Analyzer, error) {
+	lib, err := analysis.LoadLibrary(builtinCheckers, analysis.LibraryDir)
+	if err != nil {
+		return nil, err
+	}
+
 	checkersMap := make(map[analysis.Language][]analysis.Analyzer)
-	err := fs.WalkDir(builtinCheckers, ".", findYamlCheckers(checkersMap, builtinCheckers.ReadFile))
+	err = fs.WalkDir(builtinCheckers, ".", findYamlCheckers(checkersMap, lib, builtinCheckers.ReadFile))
 	return checkersMap, err
 }
 
 func LoadCustomYamlCheckers(dir string) (map[analysis.Language][]analysis.Analyzer, error) {
+	lib, err := analysis.LoadLibrary(os.DirFS(dir), analysis.LibraryDir)
+	if err != nil {
+		return nil, err
+	}
+
 	checkersMap := make(map[analysis.Language][]analysis.Analyzer)
-	err := fs.WalkDir(os.DirFS(dir), ".", findYamlCheckers(checkersMap, func(p string) ([]byte, error) {
+	err = fs.WalkDir(os.DirFS(dir), ".", findYamlCheckers(checkersMap, lib, func(p string) ([]byte, error) {
 		return os.ReadFile(filepath.Join(dir, p))
 	}))
 	return checkersMap, err
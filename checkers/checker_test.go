@@ -50,3 +50,140 @@ pattern: >
 		t.Fatalf("expected 'go_custom_test' to be loaded; got %+v", goCheckers)
 	}
 }
+
+func TestLoadCheckerInfos(t *testing.T) {
+	dir := t.TempDir()
+	const yml = `language: go
+name: go_custom_info
+message: "custom checker fired"
+category: security
+severity: critical
+pattern: (import_spec) @go_custom_info
+exclude:
+  - "vendor/**"
+description: "Flags every import."
+`
+	files := map[string]string{
+		"custom.yml":     yml,
+		"custom.test.go": "package main\n",
+		// the config file lives next to the checkers, but isn't one
+		".config.yml": "failWhen:\n  exitCode: 2\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	infos, err := LoadCheckerInfos(dir)
+	if err != nil {
+		t.Fatalf("LoadCheckerInfos: %v", err)
+	}
+
+	custom := FindCheckerInfo(infos, "go_custom_info")
+	if len(custom) != 1 {
+		t.Fatalf("expected a single 'go_custom_info' checker, got %d", len(custom))
+	}
+
+	info := custom[0]
+	if info.Source != SourceCustom || info.Kind != KindYaml {
+		t.Errorf("unexpected source/kind: %s/%s", info.Source, info.Kind)
+	}
+	if info.Example != "package main\n" {
+		t.Errorf("expected the test file to be used as example, got %q", info.Example)
+	}
+	if len(info.Exclude) != 1 || info.Exclude[0] != "vendor/**" {
+		t.Errorf("unexpected exclude globs: %v", info.Exclude)
+	}
+
+	builtin := FindCheckerInfo(infos, "go_tls_insecure")
+	if len(builtin) != 1 || builtin[0].Source != SourceBuiltin || builtin[0].Example == "" {
+		t.Errorf("expected built-in 'go_tls_insecure' with an example, got %+v", builtin)
+	}
+}
+
+func TestLoadCheckerInfosGoExample(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"weak_hash.go": `package checkers
+
+import "globstar.dev/analysis"
+
+var WeakHash = &analysis.Analyzer{
+	Name:     "no-weak-hash",
+	Language: analysis.LangPy,
+}
+`,
+		"weak_hash.test.py": "hashlib.md5()\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	infos, err := LoadCheckerInfos(dir)
+	if err != nil {
+		t.Fatalf("LoadCheckerInfos: %v", err)
+	}
+
+	custom := FindCheckerInfo(infos, "no-weak-hash")
+	if len(custom) != 1 {
+		t.Fatalf("expected a single 'no-weak-hash' checker, got %d", len(custom))
+	}
+	if custom[0].Kind != KindGo || custom[0].Example != "hashlib.md5()\n" {
+		t.Errorf("expected the test file of weak_hash.go to be used as example, got %+v", custom[0])
+	}
+}
+
+func TestLoadCustomYamlCheckersWithLibrary(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/imports.yml": `fragments:
+  - name: import-of
+    language: go
+    params:
+      path: crypto/md5
+    pattern: >
+      (import_spec path: (interpreted_string_literal) @import (#eq? @import "\"$path\""))
+`,
+		"weak_hash.yml": `language: go
+name: weak_hash
+message: "weak hash"
+category: security
+severity: critical
+patterns:
+  - use: import-of
+    as: weak_hash
+  - use: import-of
+    with: {path: crypto/sha1}
+    as: weak_hash
+`,
+	}
+	if err := os.Mkdir(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatalf("mkdir lib: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	checkersMap, err := LoadCustomYamlCheckers(dir)
+	if err != nil {
+		t.Fatalf("LoadCustomYamlCheckers: %v", err)
+	}
+
+	goCheckers := checkersMap[analysis.LangGo]
+	if len(goCheckers) != 1 || goCheckers[0].Name != "weak_hash" {
+		t.Fatalf("expected only 'weak_hash' to be loaded, the library files are not checkers; got %+v", goCheckers)
+	}
+
+	infos, err := LoadCheckerInfos(dir)
+	if err != nil {
+		t.Fatalf("LoadCheckerInfos: %v", err)
+	}
+	if custom := FindCheckerInfo(infos, "weak_hash"); len(custom) != 1 {
+		t.Errorf("expected a single 'weak_hash' checker, got %d", len(custom))
+	}
+}
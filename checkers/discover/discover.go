@@ -5,19 +5,39 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"strconv"
 	"strings"
+	"unicode"
+
+	"globstar.dev/analysis"
 )
 
-func DiscoverGoCheckers(dir string) ([]string, error) {
-	goCheckers := []string{}
+// GoCheckerInfo is the metadata of a Go checker that can be read statically from
+// its `analysis.Analyzer` definition, without building the checker.
+type GoCheckerInfo struct {
+	// Ident is the package qualified identifier of the analyzer (e.g. checkers.NoAssert)
+	Ident string
+	// FilePath is the path of the file the analyzer is declared in
+	FilePath    string
+	Name        string
+	Description string
+	Language    analysis.Language
+	Category    analysis.Category
+	Severity    analysis.Severity
+	Metadata    analysis.Metadata
+}
+
+// walkAnalyzerDecls calls fn for every package level variable in dir that is
+// initialized with a `&analysis.Analyzer{...}` composite literal.
+func walkAnalyzerDecls(dir string, fn func(pkgName, filePath, varName string, lit *ast.CompositeLit)) error {
 	fset := token.NewFileSet()
 	pkgs, err := parser.ParseDir(fset, dir, nil, parser.AllErrors&parser.SkipObjectResolution|parser.ParseComments)
 	if err != nil {
-		return goCheckers, err
+		return err
 	}
 
 	for _, pkg := range pkgs {
-		for _, file := range pkg.Files {
+		for filePath, file := range pkg.Files {
 			isExcluded := false
 			if len(file.Comments) > 0 {
 				firstCommentGroup := file.Comments[0]
@@ -91,7 +111,7 @@ func DiscoverGoCheckers(dir string) ([]string, error) {
 							continue
 						}
 
-						goCheckers = append(goCheckers, fmt.Sprintf("%s.%s", pkg.Name, valueSpec.Names[0].Name))
+						fn(pkg.Name, filePath, valueSpec.Names[0].Name, lit)
 					}
 				default:
 					continue
@@ -100,5 +120,168 @@ func DiscoverGoCheckers(dir string) ([]string, error) {
 		}
 	}
 
+	return nil
+}
+
+func DiscoverGoCheckers(dir string) ([]string, error) {
+	goCheckers := []string{}
+	err := walkAnalyzerDecls(dir, func(pkgName, _, varName string, _ *ast.CompositeLit) {
+		goCheckers = append(goCheckers, fmt.Sprintf("%s.%s", pkgName, varName))
+	})
+	if err != nil {
+		return goCheckers, err
+	}
+
 	return goCheckers, nil
 }
+
+// DescribeGoCheckers returns the metadata of all Go checkers in dir.
+// Only fields initialized with constant expressions (string literals and
+// the constants exported by the analysis package) can be recovered.
+func DescribeGoCheckers(dir string) ([]*GoCheckerInfo, error) {
+	infos := []*GoCheckerInfo{}
+	err := walkAnalyzerDecls(dir, func(pkgName, filePath, varName string, lit *ast.CompositeLit) {
+		info := &GoCheckerInfo{
+			Ident:    fmt.Sprintf("%s.%s", pkgName, varName),
+			FilePath: filePath,
+		}
+
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+
+			switch key.Name {
+			case "Name":
+				info.Name = stringFromExpr(kv.Value)
+			case "Description":
+				info.Description = stringFromExpr(kv.Value)
+			case "Language":
+				info.Language = analysis.DecodeLanguage(strings.TrimPrefix(selectorName(kv.Value), "Lang"))
+			case "Category":
+				info.Category = analysis.Category(kebabCase(strings.TrimPrefix(selectorName(kv.Value), "Category")))
+			case "Severity":
+				info.Severity = analysis.Severity(kebabCase(strings.TrimPrefix(selectorName(kv.Value), "Severity")))
+			case "Metadata":
+				info.Metadata = metadataFromExpr(kv.Value)
+			}
+		}
+
+		infos = append(infos, info)
+	})
+	if err != nil {
+		return infos, err
+	}
+
+	return infos, nil
+}
+
+// metadataFromExpr reads an `analysis.Metadata{...}` composite literal.
+func metadataFromExpr(expr ast.Expr) analysis.Metadata {
+	var metadata analysis.Metadata
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return metadata
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		switch key.Name {
+		case "CWE":
+			metadata.CWE = stringsFromExpr(kv.Value)
+		case "OWASP":
+			metadata.OWASP = stringsFromExpr(kv.Value)
+		case "References":
+			metadata.References = stringsFromExpr(kv.Value)
+		case "Tags":
+			metadata.Tags = stringsFromExpr(kv.Value)
+		case "Confidence":
+			metadata.Confidence = analysis.Confidence(kebabCase(strings.TrimPrefix(selectorName(kv.Value), "Confidence")))
+		case "Author":
+			metadata.Author = stringFromExpr(kv.Value)
+		case "Version":
+			metadata.Version = stringFromExpr(kv.Value)
+		}
+	}
+
+	return metadata
+}
+
+// stringsFromExpr evaluates a `[]string{...}` composite literal of string literals.
+func stringsFromExpr(expr ast.Expr) []string {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, elt := range lit.Elts {
+		if s := stringFromExpr(elt); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// stringFromExpr evaluates a string literal, or a concatenation of string literals.
+func stringFromExpr(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return ""
+		}
+		s, err := strconv.Unquote(expr.Value)
+		if err != nil {
+			return ""
+		}
+		return s
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return ""
+		}
+		return stringFromExpr(expr.X) + stringFromExpr(expr.Y)
+	case *ast.ParenExpr:
+		return stringFromExpr(expr.X)
+	}
+
+	return ""
+}
+
+// selectorName returns "Bar" for an expression of the form `foo.Bar`.
+func selectorName(expr ast.Expr) string {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	return sel.Sel.Name
+}
+
+// kebabCase converts an identifier like "BugRisk" to "bug-risk".
+func kebabCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
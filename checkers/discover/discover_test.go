@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"globstar.dev/analysis"
 )
 
 func TestDiscoverGoCheckers(t *testing.T) {
@@ -74,3 +77,43 @@ func TestDiscoverGoCheckers(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeGoCheckers(t *testing.T) {
+	infos, err := DescribeGoCheckers(filepath.Join("fixtures", "checkers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(infos) != 1 {
+		t.Fatalf("DescribeGoCheckers() = %v, want a single checker", infos)
+	}
+
+	info := infos[0]
+	if info.Ident != "checkers.NoDoubleEq" || info.Name != "no-double-eq" {
+		t.Errorf("unexpected checker identity: %s (%s)", info.Ident, info.Name)
+	}
+
+	if info.Language != analysis.LangJs {
+		t.Errorf("Language = %v, want %v", info.Language, analysis.LangJs)
+	}
+
+	if info.Category != analysis.CategoryBugRisk {
+		t.Errorf("Category = %v, want %v", info.Category, analysis.CategoryBugRisk)
+	}
+
+	if info.Severity != analysis.SeverityWarning {
+		t.Errorf("Severity = %v, want %v", info.Severity, analysis.SeverityWarning)
+	}
+
+	if !strings.HasPrefix(info.Description, "This checker checks for the usage of '=='") {
+		t.Errorf("unexpected description: %q", info.Description)
+	}
+
+	if info.Metadata.Confidence != analysis.ConfidenceHigh || strings.Join(info.Metadata.Tags, ",") != "comparison,js" {
+		t.Errorf("unexpected metadata: %+v", info.Metadata)
+	}
+
+	if filepath.Base(info.FilePath) != "no_double_eq.go" {
+		t.Errorf("FilePath = %s, want no_double_eq.go", info.FilePath)
+	}
+}
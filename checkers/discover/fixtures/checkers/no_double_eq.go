@@ -11,7 +11,11 @@ var NoDoubleEq *analysis.Analyzer = &analysis.Analyzer{
 	Description: "This checker checks for the usage of '==' in JavaScript code. It reports an issue if '==' is used for comparison. It suggests using '===' instead.",
 	Category:    analysis.CategoryBugRisk,
 	Severity:    analysis.SeverityWarning,
-	Run:         noDoubleEq,
+	Metadata: analysis.Metadata{
+		Confidence: analysis.ConfidenceHigh,
+		Tags:       []string{"comparison", "js"},
+	},
+	Run: noDoubleEq,
 }
 
 func noDoubleEq(pass *analysis.Pass) (interface{}, error) {
@@ -0,0 +1,232 @@
+package checkers
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"globstar.dev/analysis"
+	"globstar.dev/checkers/discover"
+)
+
+// CheckerSource tells whether a checker ships with Globstar or
+// is defined in the repository's checker directory.
+type CheckerSource string
+
+const (
+	SourceBuiltin CheckerSource = "builtin"
+	SourceCustom  CheckerSource = "custom"
+)
+
+// CheckerKind is the interface a checker is written with.
+type CheckerKind string
+
+const (
+	KindYaml CheckerKind = "yaml"
+	KindGo   CheckerKind = "go"
+)
+
+// CheckerInfo describes a checker independently of how it is implemented.
+type CheckerInfo struct {
+	Name        string
+	Description string
+	Language    analysis.Language
+	Category    analysis.Category
+	Severity    analysis.Severity
+	Source      CheckerSource
+	Kind        CheckerKind
+	// Path is the file the checker is defined in. For built-in checkers, it is
+	// relative to the checkers directory, and built-in Go checkers only record
+	// the directory they live in.
+	Path string
+	// (optional) Message is the message template of a YAML checker
+	Message string
+	// (optional) Include and Exclude are the path globs a YAML checker is restricted to
+	Include []string
+	Exclude []string
+	// (optional) Example is the content of the checker's test file
+	Example string
+	// (optional) Metadata has the CWE IDs, OWASP categories and other details of the checker
+	Metadata analysis.Metadata
+}
+
+// LoadCheckerInfos returns the metadata of every built-in checker
+// and every custom checker found in checkerDir.
+func LoadCheckerInfos(checkerDir string) ([]*CheckerInfo, error) {
+	infos := builtinGoCheckerInfos()
+
+	yamlInfos, err := yamlCheckerInfos(builtinCheckers, SourceBuiltin)
+	if err != nil {
+		return nil, err
+	}
+	infos = append(infos, yamlInfos...)
+
+	if _, err := os.Stat(checkerDir); err != nil {
+		if os.IsNotExist(err) {
+			return infos, nil
+		}
+		return nil, err
+	}
+
+	yamlInfos, err = yamlCheckerInfos(os.DirFS(checkerDir), SourceCustom)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range yamlInfos {
+		info.Path = filepath.Join(checkerDir, info.Path)
+	}
+	infos = append(infos, yamlInfos...)
+
+	goInfos, err := customGoCheckerInfos(checkerDir)
+	if err != nil {
+		return nil, err
+	}
+	infos = append(infos, goInfos...)
+
+	return infos, nil
+}
+
+func builtinGoCheckerInfos() []*CheckerInfo {
+	infos := []*CheckerInfo{}
+	for _, reg := range AnalyzerRegistry {
+		// test directories are relative to the repository root, while the
+		// embedded filesystem is rooted at the checkers directory
+		testDir := strings.TrimPrefix(filepath.ToSlash(reg.TestDir), "checkers/")
+		for _, analyzer := range reg.Analyzers {
+			testFile := path.Join(testDir, analyzer.Name+".test"+analysis.GetExtFromLanguage(analyzer.Language))
+			example, _ := builtinCheckers.ReadFile(testFile)
+			infos = append(infos, &CheckerInfo{
+				Name:        analyzer.Name,
+				Description: analyzer.Description,
+				Language:    analyzer.Language,
+				Category:    analyzer.Category,
+				Severity:    analyzer.Severity,
+				Source:      SourceBuiltin,
+				Kind:        KindGo,
+				Path:        path.Dir(testDir),
+				Example:     string(example),
+				Metadata:    analyzer.Metadata,
+			})
+		}
+	}
+	return infos
+}
+
+func yamlCheckerInfos(fsys fs.FS, source CheckerSource) ([]*CheckerInfo, error) {
+	lib, err := analysis.LoadLibrary(fsys, analysis.LibraryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []*CheckerInfo{}
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if p == analysis.LibraryDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		fileExt := path.Ext(p)
+		if fileExt != ".yaml" && fileExt != ".yml" {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		fileContent, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil
+		}
+
+		definitions, err := lib.DecodeYamlCheckers(fileContent)
+		if err != nil {
+			return fmt.Errorf("invalid checker '%s': %s", d.Name(), err.Error())
+		}
+
+		var checkers []analysis.Analyzer
+		for _, definition := range definitions {
+			checker, _, err := analysis.FromYaml(definition)
+			if err != nil {
+				return fmt.Errorf("invalid checker '%s': %s", d.Name(), err.Error())
+			}
+			checkers = append(checkers, checker)
+		}
+
+		for i, checker := range checkers {
+			testFile := analysis.YamlTestFileBase(p, checkers, &checkers[i]) + ".test" + analysis.GetExtFromLanguage(checker.Language)
+			example, _ := fs.ReadFile(fsys, testFile)
+
+			infos = append(infos, &CheckerInfo{
+				Name:        checker.Name,
+				Description: checker.Description,
+				Language:    checker.Language,
+				Category:    checker.Category,
+				Severity:    checker.Severity,
+				Source:      source,
+				Kind:        KindYaml,
+				Path:        p,
+				Message:     definitions[i].Message,
+				Include:     definitions[i].Include,
+				Exclude:     definitions[i].Exclude,
+				Example:     string(example),
+				Metadata:    checker.Metadata,
+			})
+		}
+		return nil
+	})
+	return infos, err
+}
+
+func customGoCheckerInfos(checkerDir string) ([]*CheckerInfo, error) {
+	goCheckers, err := discover.DescribeGoCheckers(checkerDir)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering custom Go checkers: %w", err)
+	}
+
+	infos := []*CheckerInfo{}
+	for _, checker := range goCheckers {
+		// the example sits next to the file declaring the analyzer, which
+		// isn't necessarily named after it
+		ext := ".test" + analysis.GetExtFromLanguage(checker.Language)
+		example, err := os.ReadFile(strings.TrimSuffix(checker.FilePath, filepath.Ext(checker.FilePath)) + ext)
+		if err != nil {
+			example, _ = os.ReadFile(filepath.Join(filepath.Dir(checker.FilePath), checker.Name+ext))
+		}
+		infos = append(infos, &CheckerInfo{
+			Name:        checker.Name,
+			Description: checker.Description,
+			Language:    checker.Language,
+			Category:    checker.Category,
+			Severity:    checker.Severity,
+			Source:      SourceCustom,
+			Kind:        KindGo,
+			Path:        checker.FilePath,
+			Example:     string(example),
+			Metadata:    checker.Metadata,
+		})
+	}
+	return infos, nil
+}
+
+// FindCheckerInfo returns all checkers with the given name.
+// A custom checker can share its name with a built-in one, so there may
+// be more than one match.
+func FindCheckerInfo(infos []*CheckerInfo, name string) []*CheckerInfo {
+	var found []*CheckerInfo
+	for _, info := range infos {
+		if info.Name == name {
+			found = append(found, info)
+		}
+	}
+	return found
+}
@@ -23,7 +23,10 @@ func noDoubleEq(pass *analysis.Pass) (interface{}, error) {
 			}
 
 			if operator.Content(pass.FileContext.Source) == "==" {
-				pass.Report(pass, operator, "Do not use '==' for comparison. Prefer '===' instead.")
+				pass.ReportWithFixes(pass, operator, "Do not use '==' for comparison. Prefer '===' instead.", analysis.SuggestedFix{
+					Description: "Use '===' instead",
+					Edits:       []analysis.TextEdit{analysis.ReplaceNode(operator, "===")},
+				})
 			}
 		}
 	})
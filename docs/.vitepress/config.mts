@@ -140,6 +140,7 @@ export default defineConfig({
         items: [
           { text: "CLI", link: "/reference/cli" },
           { text: "Configuration", link: "/reference/configuration" },
+          { text: "Suppressing Issues", link: "/reference/suppressions" },
           { text: "Checker YAML Interface", link: "/reference/checker-yaml" },
           { text: "Checker Go Interface", link: "/reference/checker-go" },
         ],
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"globstar.dev/analysis"
+)
+
+const (
+	baselineFileName = "baseline.json"
+	baselineVersion  = 1
+)
+
+// baselineEntry is an issue recorded in the baseline file.
+// Issues are matched against the baseline by their fingerprint,
+// the other fields are only there to make the file reviewable.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Id          string `json:"id"`
+	Filename    string `json:"filename"`
+	Line        int    `json:"line"`
+	Message     string `json:"message"`
+}
+
+// baseline is the set of pre-existing issues that `globstar check` does not report.
+type baseline struct {
+	Version int             `json:"version"`
+	Issues  []baselineEntry `json:"issues"`
+}
+
+// baselineComparison is the result of comparing the issues of a run against a baseline.
+type baselineComparison struct {
+	// newIssues are not in the baseline
+	newIssues []*analysis.Issue
+	// existingIssues are recorded in the baseline
+	existingIssues []*analysis.Issue
+	// fixed are the baseline entries for analyzed files that were not raised anymore
+	fixed []baselineEntry
+	// remaining are the baseline entries that are not fixed
+	remaining []baselineEntry
+}
+
+// checkerDirPath returns the path of the checker directory, resolved against the root directory.
+func (c *Cli) checkerDirPath() string {
+	if filepath.IsAbs(c.Config.CheckerDir) {
+		return c.Config.CheckerDir
+	}
+	return filepath.Join(c.RootDirectory, c.Config.CheckerDir)
+}
+
+// baselinePath returns the path of the baseline file, which is
+// `baseline.json` in the checker directory unless overridden.
+func (c *Cli) baselinePath() string {
+	if c.BaselinePath != "" {
+		return c.BaselinePath
+	}
+	return filepath.Join(c.checkerDirPath(), baselineFileName)
+}
+
+// readBaseline reads the baseline file at path.
+// It returns a nil baseline if the file does not exist.
+func readBaseline(path string) (*baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	bl := &baseline{}
+	if err := json.Unmarshal(content, bl); err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %w", path, err)
+	}
+
+	if bl.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", bl.Version, path)
+	}
+
+	return bl, nil
+}
+
+func (bl *baseline) write(path string) error {
+	sort.SliceStable(bl.Issues, func(i, j int) bool {
+		a, b := bl.Issues[i], bl.Issues[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Id < b.Id
+	})
+
+	content, err := json.MarshalIndent(bl, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+func newBaselineEntry(root string, issue *analysis.Issue) baselineEntry {
+	id := ""
+	if issue.Id != nil {
+		id = *issue.Id
+	}
+
+	relPath := relativePath(root, issue.Filepath)
+	return baselineEntry{
+		Fingerprint: issueFingerprint(relPath, issue),
+		Id:          id,
+		Filename:    relPath,
+		Line:        int(issue.Range().StartPoint.Row) + 1,
+		Message:     issue.Message,
+	}
+}
+
+// issueFingerprint returns the fingerprint of an issue. Issues from custom Go
+// checkers built against older versions of Globstar don't have one, and fall
+// back to a hash of their checker ID, file path and message.
+func issueFingerprint(relPath string, issue *analysis.Issue) string {
+	if issue.Fingerprint != "" {
+		return issue.Fingerprint
+	}
+
+	id := ""
+	if issue.Id != nil {
+		id = *issue.Id
+	}
+
+	sum := sha256.Sum256([]byte(id + "\x00" + filepath.ToSlash(relPath) + "\x00" + issue.Message))
+	return hex.EncodeToString(sum[:16])
+}
+
+// relativePath returns path relative to root, or path itself if that isn't possible.
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// compare splits issues into the ones that are new and the ones recorded in the baseline.
+// Baseline entries for files in analyzedFiles that no issue matched are reported as fixed.
+func (bl *baseline) compare(root string, issues []*analysis.Issue, analyzedFiles map[string]struct{}) *baselineComparison {
+	cmp := &baselineComparison{}
+
+	unmatched := make(map[string]int)
+	for _, entry := range bl.Issues {
+		unmatched[entry.Fingerprint]++
+	}
+
+	matched := make(map[string]int)
+	for _, issue := range issues {
+		fingerprint := issueFingerprint(relativePath(root, issue.Filepath), issue)
+		if unmatched[fingerprint] > 0 {
+			unmatched[fingerprint]--
+			matched[fingerprint]++
+			cmp.existingIssues = append(cmp.existingIssues, issue)
+		} else {
+			cmp.newIssues = append(cmp.newIssues, issue)
+		}
+	}
+
+	for _, entry := range bl.Issues {
+		if matched[entry.Fingerprint] > 0 {
+			matched[entry.Fingerprint]--
+			cmp.remaining = append(cmp.remaining, entry)
+			continue
+		}
+
+		// issues in files that weren't analyzed in this run may still be there
+		if _, ok := analyzedFiles[filepath.Join(root, filepath.FromSlash(entry.Filename))]; !ok {
+			cmp.remaining = append(cmp.remaining, entry)
+			continue
+		}
+
+		cmp.fixed = append(cmp.fixed, entry)
+	}
+
+	return cmp
+}
+
+// CreateBaseline records all issues currently raised in a new baseline file.
+func (c *Cli) CreateBaseline(runBuiltinCheckers, runCustomCheckers bool) error {
+	path := c.baselinePath()
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("baseline %s already exists, use `globstar baseline update` to add new issues to it", path)
+	}
+
+	result, err := c.collectIssues(runBuiltinCheckers, runCustomCheckers)
+	if err != nil {
+		return err
+	}
+
+	bl := &baseline{Version: baselineVersion, Issues: []baselineEntry{}}
+	for _, issue := range result.issues {
+		bl.Issues = append(bl.Issues, newBaselineEntry(c.RootDirectory, issue))
+	}
+
+	if err := bl.write(path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Recorded %d issues in %s\n", len(bl.Issues), path)
+	return nil
+}
+
+// UpdateBaseline adds the issues that are not in the baseline yet to it.
+// When prune is set, the baseline entries for fixed issues are removed instead.
+func (c *Cli) UpdateBaseline(runBuiltinCheckers, runCustomCheckers, prune bool) error {
+	path := c.baselinePath()
+	bl, err := readBaseline(path)
+	if err != nil {
+		return err
+	}
+
+	if bl == nil {
+		return fmt.Errorf("no baseline found at %s, use `globstar baseline create` to create one", path)
+	}
+
+	result, err := c.collectIssues(runBuiltinCheckers, runCustomCheckers)
+	if err != nil {
+		return err
+	}
+
+	cmp := bl.compare(c.RootDirectory, result.issues, result.analyzedFiles)
+	if prune {
+		bl.Issues = cmp.remaining
+		fmt.Fprintf(os.Stderr, "Removed %d fixed issues from %s\n", len(cmp.fixed), path)
+	} else {
+		for _, issue := range cmp.newIssues {
+			bl.Issues = append(bl.Issues, newBaselineEntry(c.RootDirectory, issue))
+		}
+		fmt.Fprintf(os.Stderr, "Added %d new issues to %s\n", len(cmp.newIssues), path)
+	}
+
+	return bl.write(path)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"globstar.dev/pkg/config"
+)
+
+const filepathCleanChecker = `language: go
+name: go_filepath_clean_test
+message: "Found filepath.Clean"
+category: security
+severity: critical
+pattern: >
+  (call_expression
+    function: (selector_expression
+      operand: (identifier) @pkg
+      field: (field_identifier) @func
+    )
+    (#eq? @pkg "filepath")
+    (#eq? @func "Clean")
+  ) @go_filepath_clean_test
+`
+
+// newTestProject creates a project with a single custom YAML checker
+// that flags calls to filepath.Clean.
+func newTestProject(t *testing.T) *Cli {
+	tmpDir := t.TempDir()
+
+	checkerDir := filepath.Join(tmpDir, ".globstar")
+	require.NoError(t, os.MkdirAll(checkerDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(checkerDir, "my_check.yml"), []byte(filepathCleanChecker), 0o644))
+
+	conf := &config.Config{}
+	conf.PopulateDefaults()
+	conf.CheckerDir = checkerDir
+
+	return &Cli{
+		RootDirectory: tmpDir,
+		Config:        conf,
+	}
+}
+
+func writeGoFile(t *testing.T, c *Cli, name, body string) {
+	source := "package main\n\nimport \"path/filepath\"\n\n" + body
+	require.NoError(t, os.WriteFile(filepath.Join(c.RootDirectory, name), []byte(source), 0o644))
+}
+
+func TestBaseline(t *testing.T) {
+	c := newTestProject(t)
+	writeGoFile(t, c, "legacy.go", "func legacy() {\n\t_ = filepath.Clean(\"x\")\n}\n")
+
+	require.NoError(t, c.CreateBaseline(false, true))
+	require.Error(t, c.CreateBaseline(false, true), "creating a baseline twice should fail")
+
+	bl, err := readBaseline(c.baselinePath())
+	require.NoError(t, err)
+	require.Len(t, bl.Issues, 1)
+	require.Equal(t, "legacy.go", bl.Issues[0].Filename)
+	require.Equal(t, 6, bl.Issues[0].Line)
+
+	// the pre-existing issue is hidden, even after it moves
+	writeGoFile(t, c, "legacy.go", "// moved\n\nfunc legacy() {\n\t_ = filepath.Clean(\"x\")\n}\n")
+	require.NoError(t, c.RunCheckers(false, true))
+
+	// new issues are still reported
+	writeGoFile(t, c, "new.go", "func fresh() {\n\t_ = filepath.Clean(\"y\")\n}\n")
+	err = c.RunCheckers(false, true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "found 1 issues")
+
+	c.IgnoreBaseline = true
+	err = c.RunCheckers(false, true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "found 2 issues")
+	c.IgnoreBaseline = false
+
+	require.NoError(t, c.UpdateBaseline(false, true, false))
+	bl, err = readBaseline(c.baselinePath())
+	require.NoError(t, err)
+	require.Len(t, bl.Issues, 2)
+
+	// fixing an issue makes its entry prunable
+	writeGoFile(t, c, "legacy.go", "func legacy() {}\n")
+	require.NoError(t, c.UpdateBaseline(false, true, true))
+	bl, err = readBaseline(c.baselinePath())
+	require.NoError(t, err)
+	require.Len(t, bl.Issues, 1)
+	require.Equal(t, "new.go", bl.Issues[0].Filename)
+}
@@ -27,6 +27,21 @@ type Cli struct {
 	RootDirectory string
 	Config        *config.Config
 	CmpHash       string
+	// DiffScope is either "files" (the default), to report all issues in the files changed
+	// since CmpHash, or "lines" to only report the issues on changed lines.
+	DiffScope string
+	// BaselinePath overrides the default baseline file location (see baselinePath)
+	BaselinePath string
+	// IgnoreBaseline reports all issues, even the ones recorded in the baseline
+	IgnoreBaseline bool
+	// ShowExisting reports the issues recorded in the baseline as warnings
+	ShowExisting bool
+	// Staged analyzes the content of the files staged in the git index, instead of the working directory
+	Staged bool
+
+	// fixedSources overrides the content of files on disk, with the fixes applied
+	// to them so far by `globstar fix`
+	fixedSources map[string][]byte
 }
 
 func (c *Cli) loadConfig() error {
@@ -66,7 +81,8 @@ func (c *Cli) runCustomGoAnalyzerTests() (bool, error) {
 	return true, nil
 }
 
-func (c *Cli) runCustomGoAnalyzers() ([]*analysis.Issue, []string, error) {
+// runCustomGoAnalyzers runs the custom Go checkers on the files in analysisDir.
+func (c *Cli) runCustomGoAnalyzers(analysisDir string) ([]*analysis.Issue, []string, error) {
 
 	issues := []*analysis.Issue{}
 	issuesAsText := []string{}
@@ -83,7 +99,7 @@ func (c *Cli) runCustomGoAnalyzers() ([]*analysis.Issue, []string, error) {
 		return issues, issuesAsText, err
 	}
 
-	_, stderr, err := util.RunCmd("./custom-analyzer", []string{"-path", filepath.Join(c.RootDirectory, c.Config.CheckerDir)}, c.RootDirectory)
+	_, stderr, err := util.RunCmd("./custom-analyzer", []string{"-path", analysisDir}, c.RootDirectory)
 	if err != nil && err.(*exec.ExitError).ExitCode() != 1 {
 		return issues, issuesAsText, err
 	}
@@ -145,9 +161,45 @@ to run only the built-in checkers, and --checkers=all to run both.`,
 
 					&cli.StringFlag{
 						Name:    "new-since-rev",
-						Usage:   "Specify which commit to compare the head with to get changed file for analysis. Use --new-since-rev={commit-hash}",
+						Usage:   "Specify which revision to compare the head with to get changed file for analysis. Accepts a commit hash, branch, tag or expression like HEAD~3",
 						Aliases: []string{"new"},
 					},
+
+					&cli.StringFlag{
+						Name:  "new-since-merge-base",
+						Usage: "Analyze the files changed since the merge base of HEAD and the given revision, e.g. --new-since-merge-base=origin/main for pull requests",
+					},
+
+					&cli.BoolFlag{
+						Name:  "staged",
+						Usage: "Analyze the content of the files staged in the git index, instead of the working directory. Meant for pre-commit hooks",
+					},
+
+					&cli.StringFlag{
+						Name:  "diff-scope",
+						Usage: "With --new-since-rev or --new-since-merge-base, report all issues in changed files (--diff-scope=files, the default) or only the issues on added and modified lines (--diff-scope=lines)",
+						Value: diffScopeFiles,
+					},
+
+					&cli.BoolFlag{
+						Name:  "report-unused-skipcq",
+						Usage: "Report skipcq comments that suppress no issue, or that name checkers that don't exist",
+					},
+
+					&cli.StringFlag{
+						Name:  "baseline",
+						Usage: "Path to the baseline file. Defaults to baseline.json in the .globstar directory",
+					},
+
+					&cli.BoolFlag{
+						Name:  "no-baseline",
+						Usage: "Report all issues, including the ones recorded in the baseline",
+					},
+
+					&cli.BoolFlag{
+						Name:  "show-existing",
+						Usage: "Also print the issues recorded in the baseline, marked as existing. They do not fail the check",
+					},
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					ignorePattern := cmd.String("ignore")
@@ -156,18 +208,185 @@ to run only the built-in checkers, and --checkers=all to run both.`,
 					}
 
 					commitHash := cmd.String("new-since-rev")
+					mergeBaseRev := cmd.String("new-since-merge-base")
+					if commitHash != "" && mergeBaseRev != "" {
+						return fmt.Errorf("--new-since-rev and --new-since-merge-base can't be used together")
+					}
+
+					if mergeBaseRev != "" {
+						mergeBase, err := c.MergeBase(mergeBaseRev)
+						if err != nil {
+							return err
+						}
+						commitHash = mergeBase
+					}
 					c.CmpHash = commitHash
 
-					checkers := cmd.String("checkers")
-					if checkers == "local" {
-						return c.RunCheckers(false, true)
-					} else if checkers == "builtin" {
-						return c.RunCheckers(true, false)
-					} else if checkers == "all" || checkers == "" {
-						return c.RunCheckers(true, true)
+					c.Staged = cmd.Bool("staged")
+					if c.Staged && c.CmpHash != "" {
+						return fmt.Errorf("--staged can't be used with --new-since-rev or --new-since-merge-base")
+					}
+
+					c.DiffScope = cmd.String("diff-scope")
+					if err := validateDiffScope(c.DiffScope, c.CmpHash); err != nil {
+						return err
+					}
+
+					if cmd.Bool("report-unused-skipcq") {
+						c.Config.ReportUnusedSkipcq = true
+					}
+
+					c.BaselinePath = cmd.String("baseline")
+					c.IgnoreBaseline = cmd.Bool("no-baseline")
+					c.ShowExisting = cmd.Bool("show-existing")
+
+					runBuiltin, runCustom, err := parseCheckersFlag(cmd.String("checkers"))
+					if err != nil {
+						return err
+					}
+					return c.RunCheckers(runBuiltin, runCustom)
+				},
+			},
+			{
+				Name:  "fix",
+				Usage: "Apply the fixes suggested by the checkers",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "checkers",
+						Usage:   "Which checkers to run: 'local', 'builtin' or 'all' (default)",
+						Aliases: []string{"c"},
+					},
+					&cli.StringSliceFlag{
+						Name:  "checker",
+						Usage: "Only apply the fixes for the issues of this checker ID. Can be repeated",
+					},
+					&cli.StringSliceFlag{
+						Name:  "path",
+						Usage: "Only fix the files matching this glob, relative to the project root. Can be repeated",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print the fixes as a unified diff instead of applying them",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Also fix files with uncommitted changes",
+					},
+					&cli.IntFlag{
+						Name:  "max-rounds",
+						Usage: "The maximum number of times the checkers are run again on the fixed files",
+						Value: defaultMaxFixRounds,
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					runBuiltin, runCustom, err := parseCheckersFlag(cmd.String("checkers"))
+					if err != nil {
+						return err
+					}
+					return c.Fix(os.Stdout, runBuiltin, runCustom, FixOptions{
+						CheckerIds: cmd.StringSlice("checker"),
+						Paths:      cmd.StringSlice("path"),
+						DryRun:     cmd.Bool("dry-run"),
+						Force:      cmd.Bool("force"),
+						MaxRounds:  int(cmd.Int("max-rounds")),
+					})
+				},
+			},
+			{
+				Name:      "query",
+				Usage:     "Find the code matching a code pattern, with $X metavariables and ... ellipses",
+				ArgsUsage: "<code-pattern>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "lang",
+						Usage:    "The language of the pattern, e.g. --lang=py",
+						Aliases:  []string{"l"},
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "explain",
+						Usage: "Print the tree-sitter query the pattern compiles to instead of running it",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					pattern := cmd.Args().First()
+					if pattern == "" {
+						return fmt.Errorf("a code pattern is required, e.g. globstar query --lang py 'eval($X)'")
 					}
-					return fmt.Errorf("invalid value for --checkers flag, must be one of 'local', 'builtin' or 'all', got %s", checkers)
 
+					return c.Query(os.Stdout, cmd.String("lang"), pattern, cmd.Bool("explain"))
+				},
+			},
+			{
+				Name:  "hook",
+				Usage: "Manage the git hooks that run globstar",
+				Commands: []*cli.Command{
+					{
+						Name:  "install",
+						Usage: "Install a pre-commit hook that runs `globstar check --staged`",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "Replace an existing pre-commit hook",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							return c.InstallHook(cmd.Bool("force"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "baseline",
+				Usage: "Record the issues currently in the project, so that check only reports new ones",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "baseline",
+						Usage: "Path to the baseline file. Defaults to baseline.json in the .globstar directory",
+					},
+					&cli.StringFlag{
+						Name:    "checkers",
+						Usage:   "Which checkers to run: 'local', 'builtin' or 'all' (default)",
+						Aliases: []string{"c"},
+					},
+				},
+				Commands: []*cli.Command{
+					{
+						Name:  "create",
+						Usage: "Create the baseline from all the issues currently raised",
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							c.BaselinePath = cmd.String("baseline")
+							runBuiltin, runCustom, err := parseCheckersFlag(cmd.String("checkers"))
+							if err != nil {
+								return err
+							}
+							return c.CreateBaseline(runBuiltin, runCustom)
+						},
+					},
+					{
+						Name:  "update",
+						Usage: "Add the issues that are not in the baseline yet to it",
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							c.BaselinePath = cmd.String("baseline")
+							runBuiltin, runCustom, err := parseCheckersFlag(cmd.String("checkers"))
+							if err != nil {
+								return err
+							}
+							return c.UpdateBaseline(runBuiltin, runCustom, false)
+						},
+					},
+					{
+						Name:  "prune",
+						Usage: "Remove the issues that have been fixed from the baseline",
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							c.BaselinePath = cmd.String("baseline")
+							runBuiltin, runCustom, err := parseCheckersFlag(cmd.String("checkers"))
+							if err != nil {
+								return err
+							}
+							return c.UpdateBaseline(runBuiltin, runCustom, true)
+						},
+					},
 				},
 			},
 			{
@@ -232,6 +451,85 @@ to run only the built-in checkers, and --checkers=all to run both.`,
 					return nil
 				},
 			},
+			{
+				Name:      "desc",
+				Usage:     "Explain what a checker looks for, with examples",
+				ArgsUsage: "<checker-id>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the checker details as JSON",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					id := cmd.Args().First()
+					if id == "" {
+						return fmt.Errorf("a checker ID is required, e.g. globstar desc go_tls_insecure")
+					}
+
+					return c.DescribeChecker(os.Stdout, id, cmd.Bool("json"))
+				},
+			},
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List the built-in and custom checkers, and whether the config enables them",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "source",
+						Usage: "Only list 'builtin' or 'custom' checkers",
+					},
+					&cli.StringFlag{
+						Name:  "kind",
+						Usage: "Only list 'yaml' or 'go' checkers",
+					},
+					&cli.StringFlag{
+						Name:  "language",
+						Usage: "Only list checkers for a language, e.g. --language=py",
+					},
+					&cli.StringFlag{
+						Name:  "severity",
+						Usage: "Only list checkers with a severity",
+					},
+					&cli.StringFlag{
+						Name:  "category",
+						Usage: "Only list checkers in a category",
+					},
+					&cli.BoolFlag{
+						Name:  "enabled",
+						Usage: "Only list the checkers enabled by the config",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print one JSON object per checker",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					filter := listFilter{
+						Source:      cmd.String("source"),
+						Kind:        cmd.String("kind"),
+						Language:    cmd.String("language"),
+						Severity:    cmd.String("severity"),
+						Category:    cmd.String("category"),
+						EnabledOnly: cmd.Bool("enabled"),
+					}
+
+					return c.ListCheckers(os.Stdout, filter, cmd.Bool("json"))
+				},
+			},
+			{
+				Name:  "suppressions",
+				Usage: "List the skipcq comments in the project, with the checkers they suppress, their reason and expiry date",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print one JSON object per skipcq comment",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return c.ListSuppressions(os.Stdout, cmd.Bool("json"))
+				},
+			},
 			{
 				Name:    "build",
 				Aliases: []string{"b"},
@@ -251,6 +549,34 @@ to run only the built-in checkers, and --checkers=all to run both.`,
 	return err
 }
 
+// parseCheckersFlag returns whether to run the built-in and the custom
+// checkers for a value of the --checkers flag.
+func parseCheckersFlag(checkers string) (runBuiltin bool, runCustom bool, err error) {
+	switch checkers {
+	case "local":
+		return false, true, nil
+	case "builtin":
+		return true, false, nil
+	case "all", "":
+		return true, true, nil
+	}
+	return false, false, fmt.Errorf("invalid value for --checkers flag, must be one of 'local', 'builtin' or 'all', got %s", checkers)
+}
+
+// validateDiffScope checks the value of the --diff-scope flag. The changed lines are
+// the lines changed since cmpHash, so "lines" needs a revision to compare with.
+func validateDiffScope(scope, cmpHash string) error {
+	if scope != diffScopeFiles && scope != diffScopeLines {
+		return fmt.Errorf("invalid value for --diff-scope flag, must be one of 'files' or 'lines', got %s", scope)
+	}
+
+	if scope == diffScopeLines && cmpHash == "" {
+		return fmt.Errorf("--diff-scope=lines needs --new-since-rev or --new-since-merge-base")
+	}
+
+	return nil
+}
+
 func (c *Cli) buildCustomGoCheckers() error {
 	// verify that the checker directory exists
 	if _, err := os.Stat(c.Config.CheckerDir); err != nil {
@@ -296,6 +622,10 @@ func (c *Cli) buildCustomGoCheckers() error {
 type checkResult struct {
 	issues          []*analysis.Issue
 	numFilesChecked int
+	// analyzedFiles is the set of paths of all the files that were analyzed
+	analyzedFiles map[string]struct{}
+	// ignoredCounts is the number of issues ignored by each of the ignore rules in the config
+	ignoredCounts []int
 }
 
 func (lr *checkResult) GetExitStatus(conf *config.Config) int {
@@ -311,11 +641,20 @@ func (lr *checkResult) GetExitStatus(conf *config.Config) int {
 				return conf.FailWhen.ExitCode
 			}
 		}
+
+		if conf.FailWhen.MatchesMetadata(issue.Metadata.Fields()) {
+			return conf.FailWhen.ExitCode
+		}
 	}
 
 	return 0
 }
 
+const (
+	diffScopeFiles = "files"
+	diffScopeLines = "lines"
+)
+
 var defaultIgnoreDirs = []string{
 	"checkers",
 	"node_modules",
@@ -334,14 +673,100 @@ var defaultIgnoreDirs = []string{
 func (c *Cli) RunCheckers(runBuiltinCheckers, runCustomCheckers bool) error {
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
+	result, err := c.collectIssues(runBuiltinCheckers, runCustomCheckers)
+	if err != nil {
+		return err
+	}
+
+	if c.CmpHash != "" && c.DiffScope == diffScopeLines {
+		changedLines, err := c.GetChangedLines(c.CmpHash)
+		if err != nil {
+			return err
+		}
+
+		var issuesOnChangedLines []*analysis.Issue
+		for _, issue := range result.issues {
+			if issueInLineRanges(issue, changedLines[issue.Filepath]) {
+				issuesOnChangedLines = append(issuesOnChangedLines, issue)
+			}
+		}
+		result.issues = issuesOnChangedLines
+	}
+
+	existingIssues := []*analysis.Issue{}
+	fixedCount := 0
+	if !c.IgnoreBaseline {
+		bl, err := readBaseline(c.baselinePath())
+		if err != nil {
+			return err
+		}
+
+		if bl != nil {
+			cmp := bl.compare(c.RootDirectory, result.issues, result.analyzedFiles)
+			result.issues = cmp.newIssues
+			existingIssues = cmp.existingIssues
+			fixedCount = len(cmp.fixed)
+		}
+	}
+
+	for _, issue := range result.issues {
+		txt, _ := issue.AsText()
+		log.Error().Msg(string(txt))
+	}
+
+	if c.ShowExisting {
+		for _, issue := range existingIssues {
+			txt, _ := issue.AsText()
+			log.Warn().Msgf("%s (existing)", txt)
+		}
+	}
+
+	// FIXME: go based checkers do not increment the numFilesChecked counter
+	if result.numFilesChecked > 0 {
+		log.Info().Msgf("Analyzed %d files and found %d issues.", result.numFilesChecked, len(result.issues))
+	} else {
+		log.Info().Msg("No files to analyze")
+	}
+
+	ignoredCount := 0
+	var ignoredByRule []string
+	for i, count := range result.ignoredCounts {
+		if count > 0 {
+			ignoredByRule = append(ignoredByRule, fmt.Sprintf("rule %d: %d", i+1, count))
+		}
+		ignoredCount += count
+	}
+	if ignoredCount > 0 {
+		log.Info().Msgf("Ignored %d issues with the ignore rules in the config (%s).", ignoredCount, strings.Join(ignoredByRule, ", "))
+	}
+
+	if len(existingIssues) > 0 || fixedCount > 0 {
+		log.Info().Msgf("Baseline: %d new, %d existing and %d fixed issues.", len(result.issues), len(existingIssues), fixedCount)
+	}
+
+	exitStatus := result.GetExitStatus(c.Config)
+	if exitStatus != 0 {
+		fmt.Fprintf(os.Stderr, "Found %d issues\n", len(result.issues))
+		return fmt.Errorf("found %d issues", len(result.issues))
+	}
+
+	return nil
+}
+
+// collectIssues runs the selected checkers on the project and returns the issues they raise.
+func (c *Cli) collectIssues(runBuiltinCheckers, runCustomCheckers bool) (*checkResult, error) {
 	patternCheckers := make(map[analysis.Language][]analysis.Analyzer)
 
 	var goAnalyzers []*analysis.Analyzer
 	if runBuiltinCheckers {
-		goAnalyzers = checkers.LoadGoCheckers()
+		for _, analyzer := range checkers.LoadGoCheckers() {
+			if c.Config.IsCheckerEnabled(analyzer.Name, analyzer.Metadata.Fields()) {
+				goAnalyzers = append(goAnalyzers, analyzer)
+			}
+		}
 		builtInPatternCheckers, err := checkers.LoadBuiltinYamlCheckers()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// merge the built-in checkers with the custom checkers
@@ -357,7 +782,7 @@ func (c *Cli) RunCheckers(runBuiltinCheckers, runCustomCheckers bool) error {
 	if runCustomCheckers {
 		customYamlCheckers, err := checkers.LoadCustomYamlCheckers(c.Config.CheckerDir)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// merge customYamlCheckers into yamlCheckers
@@ -370,13 +795,30 @@ func (c *Cli) RunCheckers(runBuiltinCheckers, runCustomCheckers bool) error {
 		}
 	}
 
-	result := checkResult{}
+	result := &checkResult{
+		analyzedFiles: make(map[string]struct{}),
+	}
+
+	// with --staged, the staged content of the changed files is analyzed instead of the working directory
+	var stagedFiles []*analysis.ParseResult
+	if c.Staged {
+		var err error
+		stagedFiles, err = c.parseStagedFiles()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range stagedFiles {
+			result.numFilesChecked++
+			result.analyzedFiles[file.FilePath] = struct{}{}
+		}
+	}
 
 	changedFileMap := map[string]struct{}{}
 	if c.CmpHash != "" {
 		filesToAnalyze, err := c.GetChangedFiles(c.CmpHash)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		//Creating a map instead of slices to enhance performance of large codebases.
@@ -385,50 +827,53 @@ func (c *Cli) RunCheckers(runBuiltinCheckers, runCustomCheckers bool) error {
 		}
 	}
 
-	err := filepath.Walk(c.RootDirectory, func(path string, d fs.FileInfo, err error) error {
-		if err != nil {
-			// skip this path
-			return nil
-		}
-
-		if d.IsDir() {
-			if c.Config.ShouldExcludePath(path) || slices.Contains(defaultIgnoreDirs, d.Name()) {
-				return filepath.SkipDir
+	if !c.Staged {
+		err := filepath.Walk(c.RootDirectory, func(path string, d fs.FileInfo, err error) error {
+			if err != nil {
+				// skip this path
+				return nil
 			}
 
-			return nil
-		}
+			if d.IsDir() {
+				if c.Config.ShouldExcludePath(path) || slices.Contains(defaultIgnoreDirs, d.Name()) {
+					return filepath.SkipDir
+				}
 
-		if d.Mode()&fs.ModeSymlink != 0 {
-			// skip symlinks
-			return nil
-		}
+				return nil
+			}
 
-		if c.Config.ShouldExcludePath(path) {
-			return nil
-		}
+			if d.Mode()&fs.ModeSymlink != 0 {
+				// skip symlinks
+				return nil
+			}
 
-		// Only run if the incremental flag is provided.
-		if c.CmpHash != "" {
-			// Skip the path if it's not included in the changed files.
-			_, isChanged := changedFileMap[path]
-			if !isChanged {
+			if c.Config.ShouldExcludePath(path) {
 				return nil
 			}
-		}
 
-		language := analysis.LanguageFromFilePath(path)
-		if language == analysis.LangUnknown {
-			return nil
-		}
+			// Only run if the incremental flag is provided.
+			if c.CmpHash != "" {
+				// Skip the path if it's not included in the changed files.
+				_, isChanged := changedFileMap[path]
+				if !isChanged {
+					return nil
+				}
+			}
 
-		result.numFilesChecked++
+			language := analysis.LanguageFromFilePath(path)
+			if language == analysis.LangUnknown {
+				return nil
+			}
 
-		return nil
-	})
+			result.numFilesChecked++
+			result.analyzedFiles[path] = struct{}{}
 
-	if err != nil {
-		return err
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	fileFilter := func(filename string) bool {
@@ -439,28 +884,37 @@ func (c *Cli) RunCheckers(runBuiltinCheckers, runCustomCheckers bool) error {
 		return true
 	}
 
-	if len(goAnalyzers) > 0 {
-		goIssues, err := analysis.RunAnalyzers(
-			c.RootDirectory,
-			goAnalyzers,
-			fileFilter,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to run Go-based analyzers: %w", err)
+	// the files are parsed once, and shared by the runs of the Go and YAML analyzers
+	files := stagedFiles
+	parsed := c.Staged
+	ranCheckers := make(map[string]*analysis.Analyzer)
+	runAnalyzers := func(analyzers []*analysis.Analyzer) ([]*analysis.Issue, error) {
+		if !parsed {
+			var err error
+			files, err = analysis.ParseFiles(c.RootDirectory, fileFilter)
+			if err != nil {
+				return nil, err
+			}
+
+			files, err = c.overrideFixedSources(files)
+			if err != nil {
+				return nil, err
+			}
+			parsed = true
 		}
-		for _, issue := range goIssues {
-			txt, _ := issue.AsText()
-			log.Error().Msg(string(txt))
 
-			result.issues = append(result.issues, &analysis.Issue{
-				Filepath: issue.Filepath,
-				Message:  issue.Message,
-				Severity: analysis.Severity(issue.Severity),
-				Category: analysis.Category(issue.Category),
-				Node:     issue.Node,
-				Id:       issue.Id,
-			})
+		for _, analyzer := range analyzers {
+			ranCheckers[analyzer.Name] = analyzer
+		}
+		return analysis.RunAnalyzersOnFiles(c.RootDirectory, files, analyzers)
+	}
+
+	if len(goAnalyzers) > 0 {
+		goIssues, err := runAnalyzers(goAnalyzers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run Go-based analyzers: %w", err)
 		}
+		result.issues = append(result.issues, goIssues...)
 	}
 
 	// Flatten the per-language pattern checkers map into a slice and run them
@@ -471,80 +925,75 @@ func (c *Cli) RunCheckers(runBuiltinCheckers, runCustomCheckers bool) error {
 	for _, checkers := range patternCheckers {
 		for i := range checkers {
 			analyzer := &checkers[i]
+			if !c.Config.IsCheckerEnabled(analyzer.Name, analyzer.Metadata.Fields()) {
+				continue
+			}
 			yamlAnalyzers = append(yamlAnalyzers, analyzer)
 			yamlAnalyzerByName[analyzer.Name] = analyzer
 		}
 	}
 
 	if len(yamlAnalyzers) > 0 {
-		yamlIssues, err := analysis.RunAnalyzers(
-			c.RootDirectory,
-			yamlAnalyzers,
-			fileFilter,
-		)
+		yamlIssues, err := runAnalyzers(yamlAnalyzers)
 		if err != nil {
-			return fmt.Errorf("failed to run YAML pattern analyzers: %w", err)
+			return nil, fmt.Errorf("failed to run YAML pattern analyzers: %w", err)
 		}
 		for _, issue := range yamlIssues {
-			txt, _ := issue.AsText()
-			log.Error().Msg(string(txt))
-
 			// Look up the originating analyzer so we can preserve severity
-			// and category on the reported issue.
-			severity := analysis.Severity(issue.Severity)
-			category := analysis.Category(issue.Category)
+			// and category on the reported issue. The issue itself is kept,
+			// since it carries the range the checker reported.
 			if issue.Id != nil {
 				if a, ok := yamlAnalyzerByName[*issue.Id]; ok {
-					severity = a.Severity
-					category = a.Category
+					issue.Severity = a.Severity
+					issue.Category = a.Category
 				}
 			}
 
-			result.issues = append(result.issues, &analysis.Issue{
-				Filepath: issue.Filepath,
-				Message:  issue.Message,
-				Severity: severity,
-				Category: category,
-				Node:     issue.Node,
-				Id:       issue.Id,
-			})
+			result.issues = append(result.issues, issue)
 		}
 	}
 
+	if len(c.Config.RequireSkipcqReason) > 0 {
+		result.issues = append(result.issues, c.unjustifiedSuppressions(files, ranCheckers)...)
+	}
+
 	if runCustomCheckers {
-		customGoIssues, textIssues, err := c.runCustomGoAnalyzers()
-		if err != nil {
-			return fmt.Errorf("failed to run custom Go-based analyzers: %w", err)
+		var customGoIssues []*analysis.Issue
+		var err error
+		if c.Staged {
+			customGoIssues, err = c.runCustomGoAnalyzersOnContent(stagedFiles)
+		} else {
+			customGoIssues, _, err = c.runCustomGoAnalyzers(filepath.Join(c.RootDirectory, c.Config.CheckerDir))
+			if err == nil && len(c.fixedSources) > 0 {
+				customGoIssues, err = c.rerunCustomGoAnalyzersOnFixed(customGoIssues, files)
+			}
 		}
-
-		for _, txt := range textIssues {
-			log.Error().Msg(string(txt))
+		if err != nil {
+			return nil, fmt.Errorf("failed to run custom Go-based analyzers: %w", err)
 		}
 
 		for _, issue := range customGoIssues {
-			result.issues = append(result.issues, &analysis.Issue{
-				Filepath: issue.Filepath,
-				Message:  issue.Message,
-				Severity: analysis.Severity(issue.Severity),
-				Category: analysis.Category(issue.Category),
-				Node:     issue.Node,
-				Id:       issue.Id,
-			})
+			// custom Go checkers run in a separate binary, so the
+			// checkers disabled in the config are filtered out here
+			if issue.Id != nil && !c.Config.IsCheckerEnabled(*issue.Id, issue.Metadata.Fields()) {
+				continue
+			}
+
+			result.issues = append(result.issues, issue)
 		}
 	}
 
-	// FIXME: go based checkers do not increment the numFilesChecked counter
-	if result.numFilesChecked > 0 {
-		log.Info().Msgf("Analyzed %d files and found %d issues.", result.numFilesChecked, len(result.issues))
-	} else {
-		log.Info().Msg("No files to analyze")
+	if c.Config.ReportUnusedSkipcq {
+		skipcqIssues, err := c.skipcqIssues(files, ranCheckers, runCustomCheckers)
+		if err != nil {
+			return nil, err
+		}
+		result.issues = append(result.issues, skipcqIssues...)
 	}
 
-	exitStatus := result.GetExitStatus(c.Config)
-	if exitStatus != 0 {
-		fmt.Fprintf(os.Stderr, "Found %d issues\n", len(result.issues))
-		return fmt.Errorf("found %d issues", len(result.issues))
+	if len(c.Config.Ignore) > 0 {
+		result.issues, result.ignoredCounts = c.applyIgnoreRules(result.issues, files)
 	}
 
-	return err
+	return result, nil
 }
@@ -1,8 +1,11 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -69,3 +72,153 @@ func main() {
 	require.Error(t, err, "expected RunCheckers to report a finding from the custom YAML checker")
 	require.Contains(t, err.Error(), "found 1 issues")
 }
+
+func TestDescribeChecker(t *testing.T) {
+	conf := &config.Config{}
+	conf.PopulateDefaults()
+	conf.CheckerDir = t.TempDir()
+
+	c := &Cli{Config: conf}
+
+	var out bytes.Buffer
+	err := c.DescribeChecker(&out, "go_tls_insecure", false)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "Severity:  critical")
+	require.Contains(t, out.String(), "InsecureSkipVerify")
+	require.Contains(t, out.String(), "CWE:       CWE-295")
+
+	out.Reset()
+	err = c.DescribeChecker(&out, "go_tls_insecure", true)
+	require.NoError(t, err)
+
+	var desc map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &desc))
+	require.Equal(t, "go", desc["language"])
+	require.Equal(t, "builtin", desc["source"])
+	require.Equal(t, "yaml", desc["kind"])
+	require.Equal(t, map[string]any{
+		"cwe":        []any{"CWE-295"},
+		"owasp":      []any{"A07:2021"},
+		"confidence": "high",
+		"tags":       []any{"tls", "crypto"},
+		"references": []any{"https://cwe.mitre.org/data/definitions/295.html", "https://pkg.go.dev/crypto/tls#Config"},
+	}, desc["metadata"])
+
+	err = c.DescribeChecker(&out, "no_such_checker", false)
+	require.Error(t, err)
+}
+
+func TestListCheckers(t *testing.T) {
+	conf := &config.Config{}
+	conf.PopulateDefaults()
+	conf.CheckerDir = t.TempDir()
+	conf.DisabledCheckers = []string{"go_tls_insecure"}
+
+	c := &Cli{Config: conf}
+
+	var out bytes.Buffer
+	err := c.ListCheckers(&out, listFilter{Language: "go", Kind: "yaml"}, true)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.NotEmpty(t, lines)
+
+	found := false
+	for _, line := range lines {
+		var listed map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &listed))
+		require.Equal(t, "go", listed["language"])
+		require.Equal(t, "yaml", listed["kind"])
+
+		if listed["name"] == "go_tls_insecure" {
+			found = true
+			require.Equal(t, false, listed["enabled"])
+		}
+	}
+	require.True(t, found, "expected go_tls_insecure to be listed")
+
+	out.Reset()
+	err = c.ListCheckers(&out, listFilter{Language: "go", EnabledOnly: true}, false)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out.String(), "NAME"))
+	require.NotContains(t, out.String(), "go_tls_insecure")
+
+	err = c.ListCheckers(&out, listFilter{Source: "somewhere"}, false)
+	require.Error(t, err)
+}
+
+func TestCheckerSelectors(t *testing.T) {
+	conf := &config.Config{}
+	conf.PopulateDefaults()
+	conf.CheckerDir = t.TempDir()
+	conf.EnabledCheckers = []string{"tag:tls", "no-double-eq"}
+	conf.DisabledCheckers = []string{"cwe:295"}
+	require.NoError(t, conf.Validate())
+
+	c := &Cli{Config: conf}
+
+	var out bytes.Buffer
+	require.NoError(t, c.ListCheckers(&out, listFilter{EnabledOnly: true}, true))
+
+	var enabled []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var listed map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &listed))
+		enabled = append(enabled, listed["name"].(string))
+	}
+	require.Equal(t, []string{"no-double-eq"}, enabled)
+
+	conf.EnabledCheckers = []string{"bogus:value"}
+	require.Error(t, conf.Validate())
+}
+
+func TestFailWhenMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	checkerDir := filepath.Join(tmpDir, ".globstar")
+	require.NoError(t, os.MkdirAll(checkerDir, 0o755))
+
+	checker := filepathCleanChecker + "metadata:\n  cwe: [22]\n  confidence: low\n"
+	checker = strings.Replace(checker, "severity: critical", "severity: info", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(checkerDir, "my_check.yml"), []byte(checker), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.go"), []byte("package main\n\nimport \"path/filepath\"\n\nvar _ = filepath.Clean(\"x\")\n"), 0o644))
+
+	conf := &config.Config{}
+	conf.PopulateDefaults()
+	conf.CheckerDir = checkerDir
+	c := &Cli{RootDirectory: tmpDir, Config: conf}
+
+	require.NoError(t, c.RunCheckers(false, true))
+
+	conf.FailWhen.MetadataIn = []map[string]string{{"cwe": "CWE-22", "confidence": "high"}}
+	require.NoError(t, c.RunCheckers(false, true))
+
+	conf.FailWhen.MetadataIn = append(conf.FailWhen.MetadataIn, map[string]string{"cwe": "22"})
+	require.Error(t, c.RunCheckers(false, true))
+}
+
+func TestCollectIssuesKeepsReportRange(t *testing.T) {
+	c := newTestProject(t)
+	const checker = `language: py
+name: py-call-line
+message: "call"
+category: style
+severity: info
+report-range: line
+pattern: '(call function: (identifier) @fn (#eq? @fn "foo")) @py-call-line'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(c.Config.CheckerDir, "line.yml"), []byte(checker), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(c.RootDirectory, "a.py"), []byte("def f():\n    x = foo(1,\n            2)\n"), 0o644))
+
+	result, err := c.collectIssues(false, true)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, issue := range result.issues {
+		if issue.Id != nil && *issue.Id == "py-call-line" {
+			txt, _ := issue.AsText()
+			texts = append(texts, strings.TrimPrefix(string(txt), c.RootDirectory+string(filepath.Separator)))
+		}
+	}
+	// the issue starts at the code of the line, not at the call
+	require.Equal(t, []string{"a.py:2:4:call"}, texts)
+}
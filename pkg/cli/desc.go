@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"globstar.dev/analysis"
+	"globstar.dev/checkers"
+)
+
+type checkerInfoJson struct {
+	Name        string   `json:"name"`
+	Language    string   `json:"language"`
+	Category    string   `json:"category"`
+	Severity    string   `json:"severity"`
+	Source      string   `json:"source"`
+	Kind        string   `json:"kind"`
+	Path        string   `json:"path"`
+	Message     string   `json:"message,omitempty"`
+	Description string   `json:"description"`
+	Include     []string `json:"include,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+	Example     string   `json:"example,omitempty"`
+
+	Metadata *analysis.Metadata `json:"metadata,omitempty"`
+}
+
+func newCheckerInfoJson(info *checkers.CheckerInfo) checkerInfoJson {
+	out := checkerInfoJson{
+		Name:        info.Name,
+		Language:    info.Language.String(),
+		Category:    string(info.Category),
+		Severity:    string(info.Severity),
+		Source:      string(info.Source),
+		Kind:        string(info.Kind),
+		Path:        info.Path,
+		Message:     info.Message,
+		Description: info.Description,
+		Include:     info.Include,
+		Exclude:     info.Exclude,
+		Example:     info.Example,
+	}
+
+	if !info.Metadata.IsEmpty() {
+		out.Metadata = &info.Metadata
+	}
+	return out
+}
+
+// DescribeChecker writes the details of the checker with the given ID to w.
+// With asJson set, each matching checker is written as a JSON object on its own line.
+func (c *Cli) DescribeChecker(w io.Writer, id string, asJson bool) error {
+	infos, err := checkers.LoadCheckerInfos(c.Config.CheckerDir)
+	if err != nil {
+		return err
+	}
+
+	found := checkers.FindCheckerInfo(infos, id)
+	if len(found) == 0 {
+		return fmt.Errorf("no checker found with ID '%s'", id)
+	}
+
+	color := false
+	if f, ok := w.(*os.File); ok {
+		color = useColor(f)
+	}
+
+	for i, info := range found {
+		if asJson {
+			out, err := json.Marshal(newCheckerInfoJson(info))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(out))
+			continue
+		}
+
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		writeCheckerInfo(w, info, color)
+	}
+
+	return nil
+}
+
+func writeCheckerInfo(w io.Writer, info *checkers.CheckerInfo, color bool) {
+	heading := func(s string) string {
+		if color {
+			return ansiBold + s + ansiReset
+		}
+		return s
+	}
+
+	kind := "Go"
+	if info.Kind == checkers.KindYaml {
+		kind = "YAML"
+	}
+
+	fmt.Fprintln(w, heading(info.Name))
+	fmt.Fprintf(w, "  Language:  %s\n", info.Language)
+	fmt.Fprintf(w, "  Category:  %s\n", info.Category)
+	fmt.Fprintf(w, "  Severity:  %s\n", info.Severity)
+	fmt.Fprintf(w, "  Source:    %s %s checker (%s)\n", info.Source, kind, info.Path)
+	if info.Message != "" {
+		fmt.Fprintf(w, "  Message:   %s\n", info.Message)
+	}
+	writeMetadata(w, &info.Metadata)
+
+	if info.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", heading("Description"))
+		fmt.Fprintln(w, indent(renderMarkdown(info.Description, color), "  "))
+	}
+
+	if len(info.Include) > 0 || len(info.Exclude) > 0 {
+		fmt.Fprintf(w, "\n%s\n", heading("Options"))
+		if len(info.Include) > 0 {
+			fmt.Fprintf(w, "  include: %s\n", strings.Join(info.Include, ", "))
+		}
+		if len(info.Exclude) > 0 {
+			fmt.Fprintf(w, "  exclude: %s\n", strings.Join(info.Exclude, ", "))
+		}
+	}
+
+	if info.Example != "" {
+		fmt.Fprintf(w, "\n%s\n", heading("Examples"))
+		fmt.Fprintln(w, indent(strings.TrimRight(info.Example, "\n"), "  "))
+	}
+}
+
+// writeMetadata writes the fields of the metadata that are set, aligned with the
+// other details of a checker.
+func writeMetadata(w io.Writer, metadata *analysis.Metadata) {
+	fields := []struct {
+		label  string
+		values []string
+	}{
+		{"CWE", metadata.CWE},
+		{"OWASP", metadata.OWASP},
+		{"Confidence", []string{string(metadata.Confidence)}},
+		{"Tags", metadata.Tags},
+		{"Author", []string{metadata.Author}},
+		{"Version", []string{metadata.Version}},
+		{"References", metadata.References},
+	}
+
+	for _, field := range fields {
+		values := slices.DeleteFunc(slices.Clone(field.values), func(v string) bool { return v == "" })
+		if len(values) > 0 {
+			fmt.Fprintf(w, "  %-10s %s\n", field.label+":", strings.Join(values, ", "))
+		}
+	}
+}
+
+// indent prefixes every non-empty line of s with prefix.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
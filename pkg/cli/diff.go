@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContextLines is the number of unchanged lines shown around the changes in a diff
+const diffContextLines = 3
+
+type diffLine struct {
+	// op is ' ' for an unchanged line, '-' for a removed one and '+' for an added one
+	op   byte
+	text string
+}
+
+// unifiedDiff returns the changes from oldContent to newContent in the unified diff
+// format, with path as the name of both files. It returns "" when nothing changed.
+func unifiedDiff(path, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	oldChars, newChars, lineArray := dmp.DiffLinesToChars(oldContent, newContent)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(oldChars, newChars, false), lineArray)
+
+	var lines []diffLine
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line != "" {
+				lines = append(lines, diffLine{op: op, text: line})
+			}
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", path, path)
+
+	// the line numbers of the first line in lines[i], in the old and new content
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// a hunk starts with the context before the first change, and ends at the
+		// first run of unchanged lines too long to be context of the changes around it
+		start := max(0, i-diffContextLines)
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+
+			unchanged := end
+			for unchanged < len(lines) && lines[unchanged].op == ' ' {
+				unchanged++
+			}
+			if unchanged == len(lines) || unchanged-end > 2*diffContextLines {
+				end = min(end+diffContextLines, unchanged)
+				break
+			}
+			end = unchanged
+		}
+
+		hunkOldStart, hunkNewStart := oldLine-(i-start), newLine-(i-start)
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, line := range lines[start:end] {
+			if line.op != '+' {
+				oldCount++
+			}
+			if line.op != '-' {
+				newCount++
+			}
+
+			body.WriteByte(line.op)
+			body.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		// an empty range starts at the line before it
+		if oldCount == 0 {
+			hunkOldStart--
+		}
+		if newCount == 0 {
+			hunkNewStart--
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n%s", hunkOldStart, oldCount, hunkNewStart, newCount, body.String())
+
+		for _, line := range lines[i:end] {
+			if line.op != '+' {
+				oldLine++
+			}
+			if line.op != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return out.String()
+}
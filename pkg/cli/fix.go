@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/gobwas/glob"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"globstar.dev/analysis"
+)
+
+// defaultMaxFixRounds bounds the rounds of fixes when FixOptions.MaxRounds isn't set
+const defaultMaxFixRounds = 10
+
+type FixOptions struct {
+	// CheckerIds limits the fixes to the issues of these checkers, when set
+	CheckerIds []string
+	// Paths limits the fixes to the files matching these globs, relative to the root directory
+	Paths []string
+	// DryRun prints the fixes as unified diffs, instead of writing them to the files
+	DryRun bool
+	// Force fixes files that have uncommitted changes, or that aren't in a git repository
+	Force bool
+	// MaxRounds is the maximum number of times the checkers are run again on the fixed files
+	MaxRounds int
+}
+
+// Fix applies the fixes suggested for the issues in the project.
+//
+// The first fix of every issue is applied, unless it overlaps with the fix of another
+// issue in the same file. Fixes can conflict, or raise new issues, so the checkers are
+// run again on the fixed files until no fix is left, or opts.MaxRounds is reached.
+// A fix that leaves its issue in place would be applied again every round, so the
+// rounds stop when an issue that was fixed is raised again with a fix.
+// The rounds run on the fixed content in memory, which is only written to the files
+// at the end, or printed as a diff to w with opts.DryRun.
+func (c *Cli) Fix(w io.Writer, runBuiltinCheckers, runCustomCheckers bool, opts FixOptions) error {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	var pathGlobs []glob.Glob
+	for _, pattern := range opts.Paths {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+		pathGlobs = append(pathGlobs, g)
+	}
+
+	var uncommitted map[string]struct{}
+	if !opts.Force {
+		var err error
+		uncommitted, err = c.uncommittedFiles()
+		if err != nil {
+			return fmt.Errorf("%w (use --force to fix files outside of a git repository)", err)
+		}
+	}
+
+	maxRounds := opts.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = defaultMaxFixRounds
+	}
+
+	c.fixedSources = make(map[string][]byte)
+	defer func() { c.fixedSources = nil }()
+
+	originals := make(map[string][]byte)
+	skipped := make(map[string]struct{})
+	// the fingerprints of the issues whose fix was applied
+	fixedIssues := make(map[string]struct{})
+	fixedCount := 0
+	done := false
+	converges := true
+	for round := 0; round < maxRounds && !done && converges; round++ {
+		result, err := c.collectIssues(runBuiltinCheckers, runCustomCheckers)
+		if err != nil {
+			return err
+		}
+
+		issuesByFile := c.selectFixes(result.issues, opts.CheckerIds, pathGlobs, uncommitted, skipped)
+
+		for _, path := range sortedKeys(issuesByFile) {
+			for _, issue := range issuesByFile[path] {
+				if _, ok := fixedIssues[issue.Fingerprint]; !ok || issue.Fingerprint == "" {
+					continue
+				}
+
+				rng := issue.Range()
+				log.Warn().Msgf("The fix of %s at %s:%d doesn't converge, the issue is raised again after the fix. Stopped applying fixes.",
+					*issue.Id, relativePath(c.RootDirectory, path), rng.StartPoint.Row+1)
+				converges = false
+			}
+		}
+		if !converges {
+			break
+		}
+
+		applied := 0
+		for path, issues := range issuesByFile {
+			var fixes []analysis.SuggestedFix
+			for _, issue := range issues {
+				fixes = append(fixes, issue.Fixes[0])
+			}
+
+			source, ok := c.fixedSources[path]
+			if !ok {
+				source, err = os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				originals[path] = source
+			}
+
+			fixed, count, err := applyFixes(path, source, fixes)
+			if err != nil {
+				log.Warn().Msgf("Could not fix %s: %s", relativePath(c.RootDirectory, path), err)
+				continue
+			}
+
+			if bytes.Equal(fixed, source) {
+				continue
+			}
+
+			c.fixedSources[path] = fixed
+			applied += count
+			// applyFixes applies the first count fixes
+			for _, issue := range issues[:count] {
+				fixedIssues[issue.Fingerprint] = struct{}{}
+			}
+		}
+
+		fixedCount += applied
+		done = applied == 0
+	}
+
+	if !done && converges {
+		log.Warn().Msgf("Stopped after %d rounds of fixes, some issues may still have fixes to apply.", maxRounds)
+	}
+
+	for _, path := range sortedKeys(skipped) {
+		log.Warn().Msgf("Skipped %s, which has uncommitted changes. Use --force to fix it.", relativePath(c.RootDirectory, path))
+	}
+
+	var changed []string
+	for _, path := range sortedKeys(c.fixedSources) {
+		if !bytes.Equal(c.fixedSources[path], originals[path]) {
+			changed = append(changed, path)
+		}
+	}
+
+	for _, path := range changed {
+		if opts.DryRun {
+			rel := filepath.ToSlash(relativePath(c.RootDirectory, path))
+			fmt.Fprint(w, unifiedDiff(rel, string(originals[path]), string(c.fixedSources[path])))
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, c.fixedSources[path], info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write the fixes to %s: %w", path, err)
+		}
+	}
+
+	if opts.DryRun {
+		log.Info().Msgf("Would fix %d issues in %d files.", fixedCount, len(changed))
+	} else {
+		log.Info().Msgf("Fixed %d issues in %d files.", fixedCount, len(changed))
+	}
+
+	return nil
+}
+
+// selectFixes returns the issues to fix in each file, in the order of their fixes.
+// The first fix of every issue is applied, unless it overlaps with the fix of an issue
+// before it in the file. The files in uncommitted are left alone, and added to skipped
+// when they have fixes.
+func (c *Cli) selectFixes(issues []*analysis.Issue, checkerIds []string, pathGlobs []glob.Glob, uncommitted, skipped map[string]struct{}) map[string][]*analysis.Issue {
+	var fixable []*analysis.Issue
+	for _, issue := range issues {
+		if len(issue.Fixes) == 0 || len(issue.Fixes[0].Edits) == 0 {
+			continue
+		}
+
+		if len(checkerIds) > 0 && (issue.Id == nil || !slices.Contains(checkerIds, *issue.Id)) {
+			continue
+		}
+
+		if len(pathGlobs) > 0 {
+			rel := filepath.ToSlash(relativePath(c.RootDirectory, issue.Filepath))
+			if !slices.ContainsFunc(pathGlobs, func(g glob.Glob) bool { return g.Match(rel) }) {
+				continue
+			}
+		}
+
+		if _, ok := uncommitted[issue.Filepath]; ok {
+			skipped[issue.Filepath] = struct{}{}
+			continue
+		}
+
+		fixable = append(fixable, issue)
+	}
+
+	// the fixes that come first in a file win, so that the choice doesn't depend
+	// on the order the checkers ran in
+	slices.SortStableFunc(fixable, func(a, b *analysis.Issue) int {
+		if a.Filepath != b.Filepath {
+			return strings.Compare(a.Filepath, b.Filepath)
+		}
+		return int(a.Fixes[0].Edits[0].StartByte) - int(b.Fixes[0].Edits[0].StartByte)
+	})
+
+	issuesByFile := make(map[string][]*analysis.Issue)
+	for _, issue := range fixable {
+		fix := issue.Fixes[0]
+		overlaps := slices.ContainsFunc(issuesByFile[issue.Filepath], func(other *analysis.Issue) bool {
+			return analysis.FixesOverlap(fix, other.Fixes[0])
+		})
+		if !overlaps {
+			issuesByFile[issue.Filepath] = append(issuesByFile[issue.Filepath], issue)
+		}
+	}
+
+	return issuesByFile
+}
+
+// applyFixes applies fixes to the source of the file at path, and returns the fixed
+// source with the number of fixes applied. Each fix is valid on its own, but together
+// they can leave syntax errors, in which case only the first one is applied, and the
+// others are left for the next round.
+func applyFixes(path string, source []byte, fixes []analysis.SuggestedFix) ([]byte, int, error) {
+	var edits []analysis.TextEdit
+	for _, fix := range fixes {
+		edits = append(edits, fix.Edits...)
+	}
+
+	fixed, err := analysis.ApplyEdits(source, edits)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(fixes) > 1 && introducesSyntaxErrors(path, source, fixed) {
+		fixed, err = analysis.ApplyEdits(source, fixes[0].Edits)
+		if err != nil {
+			return nil, 0, err
+		}
+		return fixed, 1, nil
+	}
+
+	return fixed, len(fixes), nil
+}
+
+func introducesSyntaxErrors(path string, source, fixed []byte) bool {
+	language := analysis.LanguageFromFilePath(path)
+	grammar := language.Grammar()
+	if grammar == nil {
+		return false
+	}
+
+	before, err := analysis.Parse(path, source, language, grammar)
+	if err != nil || before.Ast.HasError() {
+		return false
+	}
+
+	after, err := analysis.Parse(path, fixed, language, grammar)
+	return err != nil || after.Ast.HasError()
+}
+
+// overrideFixedSources replaces the parse results of the files fixed so far with
+// the parse results of their fixed content.
+func (c *Cli) overrideFixedSources(files []*analysis.ParseResult) ([]*analysis.ParseResult, error) {
+	if len(c.fixedSources) == 0 {
+		return files, nil
+	}
+
+	for i, file := range files {
+		source, ok := c.fixedSources[file.FilePath]
+		if !ok {
+			continue
+		}
+
+		fixed, err := analysis.Parse(file.FilePath, source, file.Language, file.TsLanguage)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = fixed
+	}
+
+	return files, nil
+}
+
+// rerunCustomGoAnalyzersOnFixed replaces the issues custom Go checkers raised on the
+// files fixed so far, which they read from disk, with the issues they raise on the
+// fixed content.
+func (c *Cli) rerunCustomGoAnalyzersOnFixed(issues []*analysis.Issue, files []*analysis.ParseResult) ([]*analysis.Issue, error) {
+	var remaining []*analysis.Issue
+	for _, issue := range issues {
+		if _, ok := c.fixedSources[issue.Filepath]; !ok {
+			remaining = append(remaining, issue)
+		}
+	}
+
+	var fixedFiles []*analysis.ParseResult
+	for _, file := range files {
+		if _, ok := c.fixedSources[file.FilePath]; ok {
+			fixedFiles = append(fixedFiles, file)
+		}
+	}
+
+	fixedIssues, err := c.runCustomGoAnalyzersOnContent(fixedFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(remaining, fixedIssues...), nil
+}
+
+// uncommittedFiles returns the paths of the files in the working directory that
+// are untracked, or have changes that aren't committed.
+func (c *Cli) uncommittedFiles() (map[string]struct{}, error) {
+	repo, err := git.PlainOpen(c.RootDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	files := make(map[string]struct{})
+	for path, fileStatus := range status {
+		if fileStatus.Staging == git.Unmodified && fileStatus.Worktree == git.Unmodified {
+			continue
+		}
+		files[filepath.Join(c.RootDirectory, filepath.FromSlash(path))] = struct{}{}
+	}
+
+	return files, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
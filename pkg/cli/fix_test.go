@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"globstar.dev/analysis"
+)
+
+func TestFix(t *testing.T) {
+	c := newTestProject(t)
+	c.Config.EnabledCheckers = []string{"no-double-eq"}
+
+	appPath := filepath.Join(c.RootDirectory, "app.js")
+	libPath := filepath.Join(c.RootDirectory, "lib.js")
+	require.NoError(t, os.WriteFile(appPath, []byte("if (a == b) {\n  run();\n}\n"), 0o644))
+	require.NoError(t, os.WriteFile(libPath, []byte("const same = x == y;\n"), 0o644))
+
+	repo, err := git.PlainInit(c.RootDirectory, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	commitAll(t, worktree, "Initial commit")
+
+	// a dry run prints the diff, and leaves the files alone
+	var out bytes.Buffer
+	require.NoError(t, c.Fix(&out, true, false, FixOptions{DryRun: true, Paths: []string{"app.js"}}))
+	assert.Equal(t, "--- a/app.js\n+++ b/app.js\n@@ -1,3 +1,3 @@\n-if (a == b) {\n+if (a === b) {\n   run();\n }\n", out.String())
+
+	content, err := os.ReadFile(appPath)
+	require.NoError(t, err)
+	assert.Equal(t, "if (a == b) {\n  run();\n}\n", string(content))
+
+	// files with uncommitted changes are skipped
+	require.NoError(t, os.WriteFile(libPath, []byte("const same = x == y; // wip\n"), 0o644))
+	out.Reset()
+	require.NoError(t, c.Fix(&out, true, false, FixOptions{}))
+	assert.Empty(t, out.String())
+
+	content, err = os.ReadFile(appPath)
+	require.NoError(t, err)
+	assert.Equal(t, "if (a === b) {\n  run();\n}\n", string(content))
+
+	content, err = os.ReadFile(libPath)
+	require.NoError(t, err)
+	assert.Equal(t, "const same = x == y; // wip\n", string(content))
+
+	// unless forced
+	require.NoError(t, c.Fix(&out, true, false, FixOptions{Force: true}))
+	content, err = os.ReadFile(libPath)
+	require.NoError(t, err)
+	assert.Equal(t, "const same = x === y; // wip\n", string(content))
+}
+
+func TestFixOutsideGitRepository(t *testing.T) {
+	c := newTestProject(t)
+	c.Config.EnabledCheckers = []string{"no-double-eq"}
+
+	path := filepath.Join(c.RootDirectory, "app.js")
+	require.NoError(t, os.WriteFile(path, []byte("a == b;\n"), 0o644))
+
+	require.Error(t, c.Fix(&bytes.Buffer{}, true, false, FixOptions{}))
+	require.NoError(t, c.Fix(&bytes.Buffer{}, true, false, FixOptions{Force: true}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a === b;\n", string(content))
+}
+
+func TestFixThatDoesNotConverge(t *testing.T) {
+	c := newTestProject(t)
+	c.Config.EnabledCheckers = []string{"wrap-eval"}
+
+	const checker = `language: py
+name: wrap-eval
+message: "eval"
+category: security
+severity: warning
+pattern: '(call function: (identifier) @fn (#eq? @fn "eval")) @wrap-eval'
+fix: "safe(@wrap-eval)"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(c.Config.CheckerDir, "wrap_eval.yml"), []byte(checker), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(c.RootDirectory, "app.py"), []byte("y = eval(x)\n"), 0o644))
+
+	// the issue is raised again after its fix, which is applied only once
+	var out bytes.Buffer
+	require.NoError(t, c.Fix(&out, false, true, FixOptions{DryRun: true, Force: true}))
+	assert.Equal(t, "--- a/app.py\n+++ b/app.py\n@@ -1,1 +1,1 @@\n-y = eval(x)\n+y = safe(eval(x))\n", out.String())
+}
+
+func TestSelectFixes(t *testing.T) {
+	c := newTestProject(t)
+	path := filepath.Join(c.RootDirectory, "app.js")
+
+	newIssue := func(id string, start, end uint32) *analysis.Issue {
+		return &analysis.Issue{
+			Id:       &id,
+			Filepath: path,
+			Fixes: []analysis.SuggestedFix{{
+				Edits: []analysis.TextEdit{{StartByte: start, EndByte: end, NewText: id}},
+			}},
+		}
+	}
+
+	issues := []*analysis.Issue{
+		newIssue("b", 4, 8),
+		newIssue("a", 0, 5),
+		newIssue("c", 10, 12),
+	}
+
+	// the fix that comes first wins over the fix it overlaps with
+	fixes := c.selectFixes(issues, nil, nil, nil, nil)
+	require.Len(t, fixes[path], 2)
+	assert.Equal(t, "a", *fixes[path][0].Id)
+	assert.Equal(t, "c", *fixes[path][1].Id)
+
+	fixes = c.selectFixes(issues, []string{"b"}, nil, nil, nil)
+	require.Len(t, fixes[path], 1)
+	assert.Equal(t, "b", *fixes[path][0].Id)
+
+	skipped := map[string]struct{}{}
+	fixes = c.selectFixes(issues, nil, nil, map[string]struct{}{path: {}}, skipped)
+	assert.Empty(t, fixes)
+	assert.Contains(t, skipped, path)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldContent string
+		newContent string
+		want       string
+	}{
+		{
+			name:       "no changes",
+			oldContent: "a\n",
+			newContent: "a\n",
+			want:       "",
+		},
+		{
+			name:       "separate hunks",
+			oldContent: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			newContent: "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- a/f\n+++ b/f\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name:       "close changes share a hunk",
+			oldContent: "1\n2\n3\n4\n5\n",
+			newContent: "one\n2\n3\n4\nfive\n",
+			want:       "--- a/f\n+++ b/f\n@@ -1,5 +1,5 @@\n-1\n+one\n 2\n 3\n 4\n-5\n+five\n",
+		},
+		{
+			name:       "insertion into an empty file",
+			oldContent: "",
+			newContent: "a\n",
+			want:       "--- a/f\n+++ b/f\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name:       "no newline at end of file",
+			oldContent: "a\nb",
+			newContent: "a\nc",
+			want:       "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, unifiedDiff("f", tt.oldContent, tt.newContent))
+		})
+	}
+}
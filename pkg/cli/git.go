@@ -1,93 +1,141 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
 	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"globstar.dev/analysis"
 )
 
-// GetChangedFiles returns all changes between compareHash and the current
-// state of the working directory
-func (c *Cli) GetChangedFiles(compareHash string) ([]string, error) {
-	changedFiles := []string{}
-	repo, err := git.PlainOpen(c.RootDirectory)
+// LineRange is an inclusive range of 1-indexed line numbers.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// resolveRevision returns the commit rev points to. rev can be anything
+// git understands as a revision: a (short) hash, a branch or tag name, or
+// an expression like HEAD~3 or main^2.
+func resolveRevision(repo *git.Repository, rev string) (*object.Commit, error) {
+	if rev == "" {
+		return nil, fmt.Errorf("compare hash is required")
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to open git repository: %w", err)
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", rev, err)
 	}
 
-	// Get worktree
-	worktree, err := repo.Worktree()
+	commit, err := repo.CommitObject(*hash)
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get worktree: %w", err)
+		return nil, fmt.Errorf("failed to get commit for revision %s: %w", rev, err)
 	}
 
-	// Get status between commit and working directory
-	status, err := worktree.Status()
+	return commit, nil
+}
+
+// MergeBase returns the hash of the best common ancestor of HEAD and rev,
+// which is the commit a pull request branch is compared against.
+func (c *Cli) MergeBase(rev string) (string, error) {
+	repo, err := git.PlainOpen(c.RootDirectory)
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	commit, err := resolveRevision(repo, rev)
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get status: %w", err)
+		return "", err
 	}
 
-	// Get compare commit
-	if compareHash == "" {
-		return changedFiles, fmt.Errorf("compare hash is required")
+	headCommit, err := resolveRevision(repo, "HEAD")
+	if err != nil {
+		return "", err
 	}
 
-	hash := plumbing.NewHash(compareHash)
-	compareCommit, err := repo.CommitObject(hash)
+	bases, err := headCommit.MergeBase(commit)
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get compare commit: %w", err)
+		return "", fmt.Errorf("failed to compute merge base of HEAD and %s: %w", rev, err)
 	}
 
-	// Get commit tree for comparison
-	compareTree, err := compareCommit.Tree()
+	if len(bases) == 0 {
+		return "", fmt.Errorf("HEAD and %s have no common ancestor", rev)
+	}
+
+	return bases[0].Hash.String(), nil
+}
+
+// changedPaths returns the paths, relative to the repository root, of all files
+// changed between compareRev and the current state of the working directory.
+// Each path maps to the path of the same file at compareRev, which differs for
+// renamed files and is empty for added files.
+// Submodules are skipped, their content belongs to another repository.
+func changedPaths(repo *git.Repository, compareRev string) (map[string]string, *object.Tree, error) {
+	compareCommit, err := resolveRevision(repo, compareRev)
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get compare tree: %w", err)
+		return nil, nil, err
 	}
 
-	// Get the status between the HEAD commit and the compare hash
-	// Get the HEAD commit tree for comparison
-	headRef, err := repo.Head()
+	compareTree, err := compareCommit.Tree()
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get HEAD ref: %w", err)
+		return nil, nil, fmt.Errorf("failed to get compare tree: %w", err)
 	}
 
-	headCommit, err := repo.CommitObject(headRef.Hash())
+	headCommit, err := resolveRevision(repo, "HEAD")
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get HEAD commit: %w", err)
+		return nil, nil, err
 	}
 
 	headTree, err := headCommit.Tree()
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get HEAD tree: %w", err)
+		return nil, nil, fmt.Errorf("failed to get HEAD tree: %w", err)
 	}
 
 	// Get the changes between the two trees
-	changes, err := compareTree.Diff(headTree)
+	changes, err := object.DiffTreeWithOptions(context.Background(), compareTree, headTree, object.DefaultDiffTreeOptions)
 	if err != nil {
-		return changedFiles, fmt.Errorf("failed to get changes between HEAD and compare commit: %w", err)
+		return nil, nil, fmt.Errorf("failed to get changes between HEAD and compare commit: %w", err)
 	}
 
-	changedFilesMap := map[string]struct{}{}
-	// Get the files that have changed
+	paths := map[string]string{}
 	for _, change := range changes {
 		action, err := change.Action()
 		if err != nil {
 			continue
 		}
 
-		if action == merkletrie.Delete {
+		if action == merkletrie.Delete || change.To.TreeEntry.Mode == filemode.Submodule {
 			continue
 		}
 
-		changedFilesMap[change.To.Name] = struct{}{}
+		paths[change.To.Name] = change.From.Name
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	// Get status between HEAD and working directory
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
 	for file, fileStatus := range status {
 		if fileStatus.Worktree == git.Deleted || fileStatus.Staging == git.Deleted {
-			delete(changedFilesMap, file)
+			delete(paths, file)
 			continue
 		}
 
@@ -95,12 +143,192 @@ func (c *Cli) GetChangedFiles(compareHash string) ([]string, error) {
 			continue
 		}
 
-		changedFilesMap[file] = struct{}{}
+		if _, ok := paths[file]; ok {
+			continue
+		}
+
+		// a file changed in the working directory only has the same path in the compare tree,
+		// unless it's new. Modified submodules show up as directories.
+		oldPath := ""
+		if entry, err := compareTree.FindEntry(file); err == nil {
+			if entry.Mode == filemode.Submodule {
+				continue
+			}
+			oldPath = file
+		}
+
+		if info, err := os.Stat(filepath.Join(worktree.Filesystem.Root(), file)); err == nil && info.IsDir() {
+			continue
+		}
+
+		paths[file] = oldPath
+	}
+
+	return paths, compareTree, nil
+}
+
+// GetChangedFiles returns all changes between compareHash and the current
+// state of the working directory. compareHash can be any revision, see resolveRevision.
+func (c *Cli) GetChangedFiles(compareHash string) ([]string, error) {
+	changedFiles := []string{}
+	repo, err := git.PlainOpen(c.RootDirectory)
+	if err != nil {
+		return changedFiles, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	paths, _, err := changedPaths(repo, compareHash)
+	if err != nil {
+		return changedFiles, err
 	}
 
-	for file := range changedFilesMap {
+	for file := range paths {
 		changedFiles = append(changedFiles, filepath.Join(c.RootDirectory, file))
 	}
 
 	return changedFiles, nil
 }
+
+// GetChangedLines returns the ranges of lines added or modified in every file that
+// changed between compareHash and the current state of the working directory.
+// The keys of the returned map are the same paths as returned by GetChangedFiles.
+// Renamed files are diffed against their content under the old name.
+func (c *Cli) GetChangedLines(compareHash string) (map[string][]LineRange, error) {
+	changedLines := map[string][]LineRange{}
+	repo, err := git.PlainOpen(c.RootDirectory)
+	if err != nil {
+		return changedLines, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	paths, compareTree, err := changedPaths(repo, compareHash)
+	if err != nil {
+		return changedLines, err
+	}
+
+	for relPath, oldPath := range paths {
+		// files that don't exist in the compare commit are diffed against an empty file
+		oldContent := ""
+		if oldPath != "" {
+			treeFile, err := compareTree.File(oldPath)
+			if err != nil && !errors.Is(err, object.ErrFileNotFound) {
+				return changedLines, fmt.Errorf("failed to find %s at compare commit: %w", oldPath, err)
+			}
+
+			if treeFile != nil {
+				oldContent, err = treeFile.Contents()
+				if err != nil {
+					return changedLines, fmt.Errorf("failed to read %s at compare commit: %w", oldPath, err)
+				}
+			}
+		}
+
+		file := filepath.Join(c.RootDirectory, relPath)
+		newContent, err := os.ReadFile(file)
+		if err != nil {
+			return changedLines, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+
+		changedLines[file] = diffLineRanges(oldContent, string(newContent))
+	}
+
+	return changedLines, nil
+}
+
+// diffLineRanges returns the ranges of lines in newContent that were added or
+// modified with respect to oldContent. Lines that were only removed have no
+// counterpart in newContent, and are not reported.
+func diffLineRanges(oldContent, newContent string) []LineRange {
+	var ranges []LineRange
+	line := 1
+	for _, d := range diff.Do(oldContent, newContent) {
+		numLines := strings.Count(d.Text, "\n")
+		if !strings.HasSuffix(d.Text, "\n") {
+			numLines++
+		}
+
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			line += numLines
+		case diffmatchpatch.DiffInsert:
+			ranges = append(ranges, LineRange{Start: line, End: line + numLines - 1})
+			line += numLines
+		}
+	}
+
+	return ranges
+}
+
+// issueInLineRanges reports whether the lines an issue spans intersect any of ranges.
+func issueInLineRanges(issue *analysis.Issue, ranges []LineRange) bool {
+	issueRange := issue.Range()
+	start := int(issueRange.StartPoint.Row) + 1
+	end := int(issueRange.EndPoint.Row) + 1
+	for _, r := range ranges {
+		if start <= r.End && r.Start <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStagedFiles returns the content in the git index of every file added or modified
+// in it, keyed by the path of the file in the working directory. Partially staged
+// files differ on disk, so a pre-commit check has to analyze this content instead.
+func (c *Cli) GetStagedFiles() (map[string][]byte, error) {
+	stagedFiles := map[string][]byte{}
+	repo, err := git.PlainOpen(c.RootDirectory)
+	if err != nil {
+		return stagedFiles, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return stagedFiles, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return stagedFiles, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return stagedFiles, fmt.Errorf("failed to read the git index: %w", err)
+	}
+
+	for file, fileStatus := range status {
+		switch fileStatus.Staging {
+		case git.Added, git.Modified, git.Renamed, git.Copied:
+		default:
+			continue
+		}
+
+		entry, err := index.Entry(file)
+		if err != nil {
+			return stagedFiles, fmt.Errorf("failed to find %s in the git index: %w", file, err)
+		}
+
+		if entry.Mode == filemode.Submodule {
+			continue
+		}
+
+		blob, err := repo.BlobObject(entry.Hash)
+		if err != nil {
+			return stagedFiles, fmt.Errorf("failed to get staged content of %s: %w", file, err)
+		}
+
+		reader, err := blob.Reader()
+		if err != nil {
+			return stagedFiles, fmt.Errorf("failed to read staged content of %s: %w", file, err)
+		}
+
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return stagedFiles, fmt.Errorf("failed to read staged content of %s: %w", file, err)
+		}
+
+		stagedFiles[filepath.Join(c.RootDirectory, file)] = content
+	}
+
+	return stagedFiles, nil
+}
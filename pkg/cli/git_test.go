@@ -6,10 +6,13 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -382,3 +385,300 @@ func TestGetChangedFiles(t *testing.T) {
 	})
 
 }
+
+func TestDiffLineRanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldContent string
+		newContent string
+		want       []LineRange
+	}{
+		{
+			name:       "unchanged",
+			oldContent: "a\nb\nc\n",
+			newContent: "a\nb\nc\n",
+			want:       nil,
+		},
+		{
+			name:       "new file",
+			oldContent: "",
+			newContent: "a\nb\n",
+			want:       []LineRange{{Start: 1, End: 2}},
+		},
+		{
+			name:       "modified and appended lines",
+			oldContent: "a\nb\nc\nd\n",
+			newContent: "a\nB\nc\nd\ne\nf\n",
+			want:       []LineRange{{Start: 2, End: 2}, {Start: 5, End: 6}},
+		},
+		{
+			name:       "removed lines",
+			oldContent: "a\nb\nc\n",
+			newContent: "a\nc\n",
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffLineRanges(tt.oldContent, tt.newContent))
+		})
+	}
+}
+
+func TestGetChangedLines(t *testing.T) {
+	tempDir := t.TempDir()
+	cli := &Cli{RootDirectory: tempDir}
+
+	err := copyDirectory("testdata", tempDir)
+	require.NoError(t, err, "Failed to copy test data directory")
+
+	fp := filepath.Join(tempDir, "test1.txt")
+	err = os.WriteFile(fp, []byte("one\ntwo\nthree\nfour\n"), 0644)
+	require.NoError(t, err)
+
+	repo, err := git.PlainInit(tempDir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+
+	commit, err := worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Test User",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+
+	// modify a line of a tracked file, and add an untracked one
+	err = os.WriteFile(fp, []byte("one\nTWO\nthree\nfour\n"), 0644)
+	require.NoError(t, err)
+
+	newFp := filepath.Join(tempDir, "test3.txt")
+	err = os.WriteFile(newFp, []byte("hello\nworld\n"), 0644)
+	require.NoError(t, err)
+
+	changedLines, err := cli.GetChangedLines(commit.String())
+	require.NoError(t, err)
+
+	assert.Equal(t, []LineRange{{Start: 2, End: 2}}, changedLines[fp])
+	assert.Equal(t, []LineRange{{Start: 1, End: 2}}, changedLines[newFp])
+}
+
+// commitAll stages every change in the worktree and commits it.
+func commitAll(t *testing.T, worktree *git.Worktree, message string) plumbing.Hash {
+	_, err := worktree.Add(".")
+	require.NoError(t, err)
+	return commitStaged(t, worktree, message, true)
+}
+
+// commitStaged commits the changes in the index, and the changes to tracked files with all.
+func commitStaged(t *testing.T, worktree *git.Worktree, message string, all bool) plumbing.Hash {
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		All: all,
+		Author: &object.Signature{
+			Name:  "Test User",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestGetChangedFilesRevisions(t *testing.T) {
+	tempDir := t.TempDir()
+	cli := &Cli{RootDirectory: tempDir}
+
+	err := copyDirectory("testdata", tempDir)
+	require.NoError(t, err, "Failed to copy test data directory")
+
+	repo, err := git.PlainInit(tempDir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	initial := commitAll(t, worktree, "Initial commit")
+	_, err = repo.CreateTag("v1.0.0", initial, nil)
+	require.NoError(t, err)
+
+	err = repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("stable"), initial))
+	require.NoError(t, err)
+
+	newFp := filepath.Join(tempDir, "test3.txt")
+	err = os.WriteFile(newFp, []byte("new\n"), 0644)
+	require.NoError(t, err)
+	commitAll(t, worktree, "Add a new file")
+
+	for _, rev := range []string{initial.String(), initial.String()[:7], "v1.0.0", "stable", "HEAD~1", "HEAD^"} {
+		t.Run(rev, func(t *testing.T) {
+			changedFiles, err := cli.GetChangedFiles(rev)
+			require.NoError(t, err)
+			assert.Equal(t, []string{newFp}, changedFiles)
+		})
+	}
+
+	_, err = cli.GetChangedFiles("no-such-branch")
+	require.Error(t, err)
+}
+
+func TestMergeBase(t *testing.T) {
+	tempDir := t.TempDir()
+	cli := &Cli{RootDirectory: tempDir}
+
+	err := copyDirectory("testdata", tempDir)
+	require.NoError(t, err, "Failed to copy test data directory")
+
+	repo, err := git.PlainInit(tempDir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	base := commitAll(t, worktree, "Initial commit")
+
+	// the main branch moves on after the feature branch is created
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main"), Create: true})
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "main.txt"), []byte("main\n"), 0644)
+	require.NoError(t, err)
+	commitAll(t, worktree, "Change on main")
+
+	err = worktree.Checkout(&git.CheckoutOptions{Hash: base, Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	require.NoError(t, err)
+	featureFp := filepath.Join(tempDir, "feature.txt")
+	err = os.WriteFile(featureFp, []byte("feature\n"), 0644)
+	require.NoError(t, err)
+	commitAll(t, worktree, "Change on feature")
+
+	mergeBase, err := cli.MergeBase("main")
+	require.NoError(t, err)
+	assert.Equal(t, base.String(), mergeBase)
+
+	// changes made on main since the branch was created aren't part of the diff
+	changedFiles, err := cli.GetChangedFiles(mergeBase)
+	require.NoError(t, err)
+	assert.Equal(t, []string{featureFp}, changedFiles)
+}
+
+func TestGetChangedLinesRename(t *testing.T) {
+	tempDir := t.TempDir()
+	cli := &Cli{RootDirectory: tempDir}
+
+	content := "one\ntwo\nthree\nfour\nfive\nsix\n"
+	err := os.WriteFile(filepath.Join(tempDir, "old.txt"), []byte(content), 0644)
+	require.NoError(t, err)
+
+	repo, err := git.PlainInit(tempDir, false)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	initial := commitAll(t, worktree, "Initial commit")
+
+	// rename the file and change one line of it
+	err = os.Remove(filepath.Join(tempDir, "old.txt"))
+	require.NoError(t, err)
+	newFp := filepath.Join(tempDir, "new.txt")
+	err = os.WriteFile(newFp, []byte(strings.Replace(content, "four", "FOUR", 1)), 0644)
+	require.NoError(t, err)
+	commitAll(t, worktree, "Rename file")
+
+	changedLines, err := cli.GetChangedLines(initial.String())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]LineRange{newFp: {{Start: 4, End: 4}}}, changedLines)
+}
+
+func TestValidateDiffScope(t *testing.T) {
+	assert.NoError(t, validateDiffScope(diffScopeFiles, ""))
+	assert.NoError(t, validateDiffScope(diffScopeLines, "abc123"))
+	assert.EqualError(t, validateDiffScope(diffScopeLines, ""), "--diff-scope=lines needs --new-since-rev or --new-since-merge-base")
+	assert.Error(t, validateDiffScope("hunks", "abc123"))
+}
+
+// stageSubmodule points the submodule at path to commit in the git index of repo.
+func stageSubmodule(t *testing.T, repo *git.Repository, path string, commit plumbing.Hash) {
+	idx, err := repo.Storer.Index()
+	require.NoError(t, err)
+
+	entry, err := idx.Entry(path)
+	if err != nil {
+		entry = idx.Add(path)
+	}
+	entry.Mode = filemode.Submodule
+	entry.Hash = commit
+	require.NoError(t, repo.Storer.SetIndex(idx))
+}
+
+func TestSubmodule(t *testing.T) {
+	c := newTestProject(t)
+	writeGoFile(t, c, "clean.go", "func clean() {}\n")
+
+	repo, err := git.PlainInit(c.RootDirectory, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	initial := commitAll(t, worktree, "Initial commit")
+
+	// the submodule is named like a Go file, so that it would be parsed if it was taken for one,
+	// and its own files have issues, which must not be reported
+	subRepo, err := git.PlainInit(filepath.Join(c.RootDirectory, "vendored.go"), false)
+	require.NoError(t, err)
+	subWorktree, err := subRepo.Worktree()
+	require.NoError(t, err)
+	writeGoFile(t, c, "vendored.go/lib.go", "func lib() {\n\t_ = filepath.Clean(\"x\")\n}\n")
+	subInitial := commitAll(t, subWorktree, "Initial commit")
+
+	gitmodules := filepath.Join(c.RootDirectory, ".gitmodules")
+	require.NoError(t, os.WriteFile(gitmodules, []byte("[submodule \"vendored.go\"]\n\tpath = vendored.go\n\turl = https://example.com/vendored.git\n"), 0o644))
+	_, err = worktree.Add(".gitmodules")
+	require.NoError(t, err)
+	stageSubmodule(t, repo, "vendored.go", subInitial)
+
+	assertSubmoduleSkipped := func(t *testing.T, cmpHash string, wantChanged []string) {
+		stagedFiles, err := c.GetStagedFiles()
+		require.NoError(t, err)
+		assert.NotContains(t, stagedFiles, filepath.Join(c.RootDirectory, "vendored.go"))
+
+		changedFiles, err := c.GetChangedFiles(cmpHash)
+		require.NoError(t, err)
+		assert.Equal(t, wantChanged, changedFiles)
+
+		for _, staged := range []bool{true, false} {
+			c.Staged, c.CmpHash = staged, cmpHash
+			result, err := c.collectIssues(false, true)
+			require.NoError(t, err)
+			assert.Empty(t, result.issues)
+			assert.NotContains(t, result.analyzedFiles, filepath.Join(c.RootDirectory, "vendored.go"))
+		}
+	}
+
+	t.Run("added", func(t *testing.T) {
+		assertSubmoduleSkipped(t, initial.String(), []string{gitmodules})
+
+		commitStaged(t, worktree, "Add submodule", false)
+		assertSubmoduleSkipped(t, initial.String(), []string{gitmodules})
+	})
+
+	t.Run("bumped", func(t *testing.T) {
+		head, err := repo.Head()
+		require.NoError(t, err)
+
+		writeGoFile(t, c, "vendored.go/lib.go", "func lib() {\n\t_ = filepath.Clean(\"y\")\n}\n")
+		subBumped := commitAll(t, subWorktree, "Change lib")
+		assertSubmoduleSkipped(t, head.Hash().String(), []string{})
+
+		stageSubmodule(t, repo, "vendored.go", subBumped)
+		assertSubmoduleSkipped(t, head.Hash().String(), []string{})
+
+		commitStaged(t, worktree, "Bump submodule", false)
+		assertSubmoduleSkipped(t, head.Hash().String(), []string{})
+	})
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// hookMarker identifies the hooks written by `globstar hook install`,
+// which can be replaced without --force.
+const hookMarker = "# installed by globstar hook install"
+
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `
+exec globstar check --staged
+`
+
+// hooksDir returns the directory git runs the hooks of the repository from.
+// It is core.hooksPath when set, and the hooks directory of the git directory otherwise.
+func (c *Cli) hooksDir() (string, error) {
+	repo, err := git.PlainOpen(c.RootDirectory)
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	if hooksPath := cfg.Raw.Section("core").Option("hooksPath"); hooksPath != "" {
+		if !filepath.IsAbs(hooksPath) {
+			hooksPath = filepath.Join(c.RootDirectory, hooksPath)
+		}
+		return hooksPath, nil
+	}
+
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", fmt.Errorf("failed to find the git directory of %s", c.RootDirectory)
+	}
+
+	return filepath.Join(storage.Filesystem().Root(), "hooks"), nil
+}
+
+// InstallHook writes a pre-commit hook that runs `globstar check --staged`.
+// An existing pre-commit hook that wasn't installed by globstar is only replaced with force.
+func (c *Cli) InstallHook(force bool) error {
+	dir, err := c.hooksDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, "pre-commit")
+	if existing, err := os.ReadFile(path); err == nil {
+		if !force && !strings.Contains(string(existing), hookMarker) {
+			return fmt.Errorf("a pre-commit hook already exists at %s, use --force to replace it", path)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(preCommitHook), 0o755); err != nil {
+		return err
+	}
+
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0o755); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Installed pre-commit hook at %s\n", path)
+	return nil
+}
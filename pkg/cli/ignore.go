@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"os"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"globstar.dev/analysis"
+)
+
+// applyIgnoreRules removes the issues matched by an ignore rule in the config.
+// It returns the remaining issues, and the number of issues ignored by each rule.
+// files are the parsed files the issues were raised in, other files are read from disk.
+func (c *Cli) applyIgnoreRules(issues []*analysis.Issue, files []*analysis.ParseResult) ([]*analysis.Issue, []int) {
+	sources := make(map[string][]byte)
+	for _, file := range files {
+		sources[file.FilePath] = file.Source
+	}
+
+	ignoredCounts := make([]int, len(c.Config.Ignore))
+	var remaining []*analysis.Issue
+	for _, issue := range issues {
+		id := ""
+		if issue.Id != nil {
+			id = *issue.Id
+		}
+
+		source, ok := sources[issue.Filepath]
+		if !ok {
+			// issues from custom Go checkers are raised in files that weren't parsed here
+			source, _ = os.ReadFile(issue.Filepath)
+			sources[issue.Filepath] = source
+		}
+
+		relPath := relativePath(c.RootDirectory, issue.Filepath)
+		text := issueText(issue, source)
+
+		ignored := false
+		for i := range c.Config.Ignore {
+			if c.Config.Ignore[i].Matches(id, relPath, text) {
+				ignoredCounts[i]++
+				ignored = true
+				break
+			}
+		}
+
+		if !ignored {
+			remaining = append(remaining, issue)
+		}
+	}
+
+	return remaining, ignoredCounts
+}
+
+// issueText returns the code an issue was raised on.
+func issueText(issue *analysis.Issue, source []byte) string {
+	if issue.Node != nil {
+		return issue.Node.Content(source)
+	}
+
+	r := issue.Range()
+	start, end := pointOffset(source, r.StartPoint), pointOffset(source, r.EndPoint)
+	if start >= end {
+		return ""
+	}
+	return string(source[start:end])
+}
+
+// pointOffset returns the byte offset of a point in source, clamped to the length of source.
+func pointOffset(source []byte, point sitter.Point) int {
+	offset := 0
+	for row := uint32(0); row < point.Row; row++ {
+		for offset < len(source) && source[offset] != '\n' {
+			offset++
+		}
+		if offset == len(source) {
+			return offset
+		}
+		offset++
+	}
+	return min(offset+int(point.Column), len(source))
+}
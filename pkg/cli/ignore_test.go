@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"globstar.dev/analysis"
+	"globstar.dev/pkg/config"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	c := newTestProject(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(c.RootDirectory, "gen"), 0o755))
+
+	writeGoFile(t, c, "main.go", "func f() {\n\t_ = filepath.Clean(\"a\")\n\t_ = filepath.Clean(generated)\n}\n")
+	writeGoFile(t, c, filepath.Join("gen", "api.pb.go"), "func f() {\n\t_ = filepath.Clean(\"a\")\n}\n")
+
+	result, err := c.collectIssues(false, true)
+	require.NoError(t, err)
+	require.Len(t, result.issues, 3)
+
+	c.Config.Ignore = []config.IgnoreRule{
+		{Checkers: []string{"go_*_test"}, Paths: []string{"gen/*.pb.go"}},
+		{Match: `\(generated\)`},
+		{Checkers: []string{"other-checker"}},
+	}
+	require.NoError(t, c.Config.Validate())
+
+	result, err = c.collectIssues(false, true)
+	require.NoError(t, err)
+	require.Len(t, result.issues, 1)
+	assert.Equal(t, filepath.Join(c.RootDirectory, "main.go"), result.issues[0].Filepath)
+	assert.Equal(t, uint32(5), result.issues[0].Range().StartPoint.Row)
+	assert.Equal(t, []int{1, 1, 0}, result.ignoredCounts)
+
+	// path globs don't match across directories with a single *
+	c.Config.Ignore = []config.IgnoreRule{{Paths: []string{"*.pb.go"}}}
+	require.NoError(t, c.Config.Validate())
+	result, err = c.collectIssues(false, true)
+	require.NoError(t, err)
+	require.Len(t, result.issues, 3)
+
+	c.Config.Ignore = []config.IgnoreRule{{}}
+	require.Error(t, c.Config.Validate(), "empty rules would ignore all issues")
+}
+
+func TestIssueText(t *testing.T) {
+	source := []byte("package main\n\nfunc f() {\n\t_ = filepath.Clean(x)\n}\n")
+
+	// issues from custom Go checkers only have a range
+	issue, err := analysis.IssueFromJson([]byte(`{"range":{"filename":"main.go","start":{"row":4,"column":5},"end":{"row":4,"column":22}},"id":"x"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "filepath.Clean(x)", issueText(issue, source))
+
+	issue, err = analysis.IssueFromJson([]byte(`{"range":{"filename":"main.go","start":{"row":40,"column":0},"end":{"row":41,"column":0}},"id":"x"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "", issueText(issue, source))
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"globstar.dev/analysis"
+	"globstar.dev/checkers"
+)
+
+// listFilter restricts the checkers shown by `globstar list`.
+// Empty fields match every checker.
+type listFilter struct {
+	Source      string
+	Kind        string
+	Language    string
+	Severity    string
+	Category    string
+	EnabledOnly bool
+}
+
+func (f *listFilter) validate() error {
+	if f.Source != "" && f.Source != string(checkers.SourceBuiltin) && f.Source != string(checkers.SourceCustom) {
+		return fmt.Errorf("invalid value for --source flag, must be one of 'builtin' or 'custom', got %s", f.Source)
+	}
+
+	if f.Kind != "" && f.Kind != string(checkers.KindYaml) && f.Kind != string(checkers.KindGo) {
+		return fmt.Errorf("invalid value for --kind flag, must be one of 'yaml' or 'go', got %s", f.Kind)
+	}
+
+	if f.Language != "" && analysis.DecodeLanguage(f.Language) == analysis.LangUnknown {
+		return fmt.Errorf("invalid value for --language flag: unknown language %s", f.Language)
+	}
+
+	if f.Severity != "" && !analysis.Severity(f.Severity).IsValid() {
+		return fmt.Errorf("invalid value for --severity flag: %s", f.Severity)
+	}
+
+	if f.Category != "" && !analysis.Category(f.Category).IsValid() {
+		return fmt.Errorf("invalid value for --category flag: %s", f.Category)
+	}
+
+	return nil
+}
+
+func (f *listFilter) matches(info *checkers.CheckerInfo, enabled bool) bool {
+	if f.Source != "" && string(info.Source) != f.Source {
+		return false
+	}
+
+	if f.Kind != "" && string(info.Kind) != f.Kind {
+		return false
+	}
+
+	if f.Language != "" && info.Language != analysis.DecodeLanguage(f.Language) {
+		return false
+	}
+
+	if f.Severity != "" && string(info.Severity) != f.Severity {
+		return false
+	}
+
+	if f.Category != "" && string(info.Category) != f.Category {
+		return false
+	}
+
+	return enabled || !f.EnabledOnly
+}
+
+type listedCheckerJson struct {
+	checkerInfoJson
+	Enabled bool `json:"enabled"`
+}
+
+// ListCheckers writes all checkers matching filter to w, sorted by name.
+// With asJson set, each checker is written as a JSON object on its own line.
+func (c *Cli) ListCheckers(w io.Writer, filter listFilter, asJson bool) error {
+	if err := filter.validate(); err != nil {
+		return err
+	}
+
+	infos, err := checkers.LoadCheckerInfos(c.Config.CheckerDir)
+	if err != nil {
+		return err
+	}
+
+	slices.SortStableFunc(infos, func(a, b *checkers.CheckerInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !asJson {
+		fmt.Fprintln(tw, "NAME\tSOURCE\tKIND\tLANGUAGE\tSEVERITY\tCATEGORY\tENABLED")
+	}
+
+	for _, info := range infos {
+		enabled := c.Config.IsCheckerEnabled(info.Name, info.Metadata.Fields())
+		if !filter.matches(info, enabled) {
+			continue
+		}
+
+		if asJson {
+			listed := listedCheckerJson{
+				checkerInfoJson: newCheckerInfoJson(info),
+				Enabled:         enabled,
+			}
+			// the list is meant for scanning, the examples are available through `globstar desc`
+			listed.Example = ""
+
+			out, err := json.Marshal(listed)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(out))
+			continue
+		}
+
+		enabledText := "yes"
+		if !enabled {
+			enabledText = "no"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			info.Name,
+			info.Source,
+			info.Kind,
+			info.Language,
+			info.Severity,
+			info.Category,
+			enabledText,
+		)
+	}
+
+	if asJson {
+		return nil
+	}
+
+	return tw.Flush()
+}
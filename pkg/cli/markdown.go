@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiUnderline = "\033[4m"
+	ansiCyan      = "\033[36m"
+)
+
+var (
+	mdHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdListItem   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+)
+
+// useColor reports whether output written to f should be styled.
+func useColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd())
+}
+
+// renderMarkdown formats a markdown document for display in a terminal.
+// It handles the subset of markdown used in checker descriptions: headings,
+// bold text, inline code, fenced code blocks and lists.
+// When color is false, the document is returned as-is.
+func renderMarkdown(md string, color bool) string {
+	md = strings.TrimSpace(md)
+	if !color {
+		return md
+	}
+
+	var b strings.Builder
+	inCodeBlock := false
+	for _, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+
+		if inCodeBlock {
+			b.WriteString("    " + ansiCyan + line + ansiReset + "\n")
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			b.WriteString(ansiBold + ansiUnderline + m[2] + ansiReset + "\n")
+			continue
+		}
+
+		if m := mdListItem.FindStringSubmatch(line); m != nil {
+			line = m[1] + "  • " + m[2]
+		}
+
+		line = mdBold.ReplaceAllStringFunc(line, func(s string) string {
+			return ansiBold + strings.Trim(s, "*_") + ansiReset
+		})
+		line = mdInlineCode.ReplaceAllString(line, ansiCyan+"$1"+ansiReset)
+		b.WriteString(line + "\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
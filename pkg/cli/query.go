@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"globstar.dev/analysis"
+)
+
+// queryCheckerName is the name of the checker built from the pattern of `globstar query`
+const queryCheckerName = "query"
+
+// Query finds the code in the project that matches a code pattern in language langName,
+// and writes the location and first line of each match to w. With explain set, it
+// writes the tree-sitter query the pattern compiles to instead.
+func (c *Cli) Query(w io.Writer, langName, pattern string, explain bool) error {
+	lang := analysis.DecodeLanguage(langName)
+	if lang == analysis.LangUnknown {
+		return fmt.Errorf("unknown language: %q", langName)
+	}
+
+	if explain {
+		query, err := analysis.CompileCodePattern(pattern, lang, queryCheckerName)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, query)
+		return nil
+	}
+
+	checker, _, err := analysis.FromYaml(analysis.Yaml{
+		Language:    langName,
+		Code:        queryCheckerName,
+		Message:     "matches the code pattern",
+		CodePattern: pattern,
+	})
+	if err != nil {
+		return err
+	}
+
+	files, err := analysis.ParseFiles(c.RootDirectory, func(path string) bool {
+		return !c.isIgnoredPath(path) && analysis.LanguageFromFilePath(path) == lang
+	})
+	if err != nil {
+		return err
+	}
+
+	issues, err := analysis.RunAnalyzersOnFiles(c.RootDirectory, files, []*analysis.Analyzer{&checker})
+	if err != nil {
+		return err
+	}
+
+	slices.SortStableFunc(issues, func(a, b *analysis.Issue) int {
+		if a.Filepath != b.Filepath {
+			return strings.Compare(a.Filepath, b.Filepath)
+		}
+		return int(a.Node.StartByte()) - int(b.Node.StartByte())
+	})
+
+	sources := make(map[string][]byte, len(files))
+	for _, file := range files {
+		sources[file.FilePath] = file.Source
+	}
+
+	for _, issue := range issues {
+		start := issue.Node.StartPoint()
+		line, _, _ := strings.Cut(issue.Node.Content(sources[issue.Filepath]), "\n")
+		fmt.Fprintf(w, "%s:%d:%d: %s\n", relativePath(c.RootDirectory, issue.Filepath), start.Row+1, start.Column+1, line)
+	}
+
+	return nil
+}
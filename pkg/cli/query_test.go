@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	c := newTestProject(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(c.RootDirectory, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(c.RootDirectory, "src", "app.py"), []byte("x = 1\nresult = eval(\n  code)\nprint(x)\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(c.RootDirectory, "app.js"), []byte("eval(code);\n"), 0o644))
+
+	var out bytes.Buffer
+	require.NoError(t, c.Query(&out, "py", "eval($X)", false))
+	assert.Equal(t, "src/app.py:2:10: eval(\n", out.String())
+
+	out.Reset()
+	require.NoError(t, c.Query(&out, "py", "eval($X)", true))
+	assert.Equal(t, "((call . function: (identifier) @__lit0 . arguments: (argument_list . (_) @X .) .)\n  (#eq? @__lit0 \"eval\")) @query\n", out.String())
+
+	require.Error(t, c.Query(&out, "py", "eval(", false))
+	require.Error(t, c.Query(&out, "klingon", "eval($X)", false))
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"globstar.dev/analysis"
+	"globstar.dev/checkers"
+	"globstar.dev/pkg/config"
+)
+
+// unusedSkipcqCheckerId is the checker ID of the issues raised on skipcq comments
+// that suppress nothing, when reportUnusedSkipcq is enabled.
+const unusedSkipcqCheckerId = "unused-skipcq"
+
+// skipcqIssues returns an issue for every skipcq comment in files that didn't suppress
+// any issue or has expired, and for every checker ID in a skipcq comment that no checker has.
+//
+// A comment is only reported as unused when all the checkers it names ran, in
+// ranCheckers. Custom Go checkers run in a separate binary, which applies skipcq
+// comments itself, so the comments that could apply to them are never reported as unused.
+func (c *Cli) skipcqIssues(files []*analysis.ParseResult, ranCheckers map[string]*analysis.Analyzer, ranCustomCheckers bool) ([]*analysis.Issue, error) {
+	infos, err := checkers.LoadCheckerInfos(c.Config.CheckerDir)
+	if err != nil {
+		return nil, err
+	}
+
+	knownCheckers := map[string]struct{}{unusedSkipcqCheckerId: {}}
+	untrackedCheckers := make(map[string]struct{})
+	for _, info := range infos {
+		knownCheckers[info.Name] = struct{}{}
+		if ranCustomCheckers && info.Source == checkers.SourceCustom && info.Kind == checkers.KindGo {
+			untrackedCheckers[info.Name] = struct{}{}
+		}
+	}
+
+	now := time.Now()
+	var issues []*analysis.Issue
+	for _, file := range files {
+		for _, comment := range file.SkipComments() {
+			var knownIds []string
+			for _, id := range comment.CheckerIds {
+				if _, ok := knownCheckers[id]; ok {
+					knownIds = append(knownIds, id)
+					continue
+				}
+
+				issues = append(issues, c.newSkipcqIssue(file, comment,
+					fmt.Sprintf("%s comment names unknown checker %q", comment.Kind, id)))
+			}
+
+			if comment.Expired(now) {
+				issues = append(issues, c.newSkipcqIssue(file, comment,
+					fmt.Sprintf("%s comment expired on %s", comment.Kind, comment.Until)))
+				continue
+			}
+
+			if len(comment.Suppressed) > 0 {
+				continue
+			}
+
+			if len(comment.CheckerIds) == 0 {
+				if len(untrackedCheckers) > 0 {
+					continue
+				}
+
+				issues = append(issues, c.newSkipcqIssue(file, comment,
+					fmt.Sprintf("%s comment does not suppress any issue", comment.Kind)))
+				continue
+			}
+
+			if len(knownIds) == 0 || !allTracked(knownIds, ranCheckers, untrackedCheckers) {
+				continue
+			}
+
+			issues = append(issues, c.newSkipcqIssue(file, comment,
+				fmt.Sprintf("%s comment for %s does not suppress any issue", comment.Kind, strings.Join(knownIds, ", "))))
+		}
+	}
+
+	return issues, nil
+}
+
+// allTracked reports whether all the checkers in ids ran, and had their suppressed issues tracked.
+func allTracked(ids []string, ranCheckers map[string]*analysis.Analyzer, untrackedCheckers map[string]struct{}) bool {
+	for _, id := range ids {
+		if _, ok := ranCheckers[id]; !ok {
+			return false
+		}
+		if _, ok := untrackedCheckers[id]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cli) newSkipcqIssue(file *analysis.ParseResult, comment *analysis.SkipComment, message string) *analysis.Issue {
+	id := unusedSkipcqCheckerId
+	return &analysis.Issue{
+		Filepath: file.FilePath,
+		Message:  message,
+		Severity: analysis.SeverityWarning,
+		Category: analysis.CategoryStyle,
+		Node:     comment.Node,
+		Id:       &id,
+		Fingerprint: analysis.Fingerprint(
+			id,
+			relativePath(c.RootDirectory, file.FilePath),
+			comment.Node,
+			file.Source,
+		),
+	}
+}
+
+// unjustifiedSuppressions returns the issues suppressed only by skipcq comments without
+// a reason, when requireSkipcqReason lists the category of the checker that raised them.
+// ranCheckers maps the ID of every checker that ran to its analyzer.
+func (c *Cli) unjustifiedSuppressions(files []*analysis.ParseResult, ranCheckers map[string]*analysis.Analyzer) []*analysis.Issue {
+	justified := make(map[*analysis.Issue]bool)
+	var suppressed []*analysis.Issue
+	for _, file := range files {
+		for _, comment := range file.SkipComments() {
+			for _, issue := range comment.Suppressed {
+				if _, seen := justified[issue]; !seen {
+					suppressed = append(suppressed, issue)
+				}
+				justified[issue] = justified[issue] || comment.Reason != ""
+			}
+		}
+	}
+
+	var issues []*analysis.Issue
+	for _, issue := range suppressed {
+		if justified[issue] || issue.Id == nil {
+			continue
+		}
+
+		analyzer, ok := ranCheckers[*issue.Id]
+		if !ok || !slices.Contains(c.Config.RequireSkipcqReason, config.Category(analyzer.Category)) {
+			continue
+		}
+
+		// a copy keeps the range the issue was reported with
+		unjustified := *issue
+		unjustified.Message = issue.Message + " (skipcq comments for " + string(analyzer.Category) + " issues need a reason)"
+		unjustified.Severity = analyzer.Severity
+		unjustified.Category = analyzer.Category
+		unjustified.Fixes = nil
+		issues = append(issues, &unjustified)
+	}
+
+	return issues
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"globstar.dev/pkg/config"
+)
+
+func TestUnusedSkipcq(t *testing.T) {
+	c := newTestProject(t)
+	writeGoFile(t, c, "main.go", `func used() {
+	_ = filepath.Clean("x") // skipcq: go_filepath_clean_test
+}
+
+// skipcq: go_filepath_clean_test
+func unused() {}
+
+func unknown() {
+	_, _ = filepath.Abs("x") // skipcq: no-such-checker
+}
+
+// skipcq
+func generic() {}
+
+// skipcq: avoid-assert
+func notRun() {}
+`)
+
+	result, err := c.collectIssues(false, true)
+	require.NoError(t, err)
+	assert.Empty(t, result.issues, "skipcq comments are only reported when enabled")
+
+	c.Config.ReportUnusedSkipcq = true
+	result, err = c.collectIssues(false, true)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, issue := range result.issues {
+		require.Equal(t, unusedSkipcqCheckerId, *issue.Id)
+		require.NotEmpty(t, issue.Fingerprint)
+		messages = append(messages, issue.Message)
+	}
+
+	// the comment for a builtin checker that didn't run is not reported
+	assert.Equal(t, []string{
+		"skipcq comment for go_filepath_clean_test does not suppress any issue",
+		`skipcq comment names unknown checker "no-such-checker"`,
+		"skipcq comment does not suppress any issue",
+	}, messages)
+
+	// unused skipcq comments are warnings, which don't fail the check by default
+	require.NoError(t, c.RunCheckers(false, true))
+}
+
+func TestSkipcqReasonAndExpiry(t *testing.T) {
+	c := newTestProject(t)
+	writeGoFile(t, c, "main.go", `func f() {
+	_ = filepath.Clean("a") // skipcq: go_filepath_clean_test reason="input is trusted"
+	_ = filepath.Clean("b") // skipcq: go_filepath_clean_test
+	_ = filepath.Clean("c") // skipcq: go_filepath_clean_test reason="migrating" until=2001-01-01
+}
+`)
+
+	result, err := c.collectIssues(false, true)
+	require.NoError(t, err)
+	require.Len(t, result.issues, 1, "expired suppressions should be reported again")
+	assert.Equal(t, uint32(7), result.issues[0].Range().StartPoint.Row)
+
+	c.Config.RequireSkipcqReason = []config.Category{config.CategorySecurity}
+	result, err = c.collectIssues(false, true)
+	require.NoError(t, err)
+	require.Len(t, result.issues, 2)
+
+	var lines []uint32
+	for _, issue := range result.issues {
+		lines = append(lines, issue.Range().StartPoint.Row)
+	}
+	assert.ElementsMatch(t, []uint32{6, 7}, lines)
+}
+
+func TestListSuppressions(t *testing.T) {
+	c := newTestProject(t)
+	writeGoFile(t, c, "main.go", `// skipcq-file: go_filepath_clean_test owner=@security until=2001-01-01
+
+func f() {
+	_ = filepath.Clean("a") // skipcq reason="input is trusted"
+}
+`)
+
+	var out bytes.Buffer
+	require.NoError(t, c.ListSuppressions(&out, true))
+
+	var suppressions []suppressionJson
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var suppression suppressionJson
+		require.NoError(t, json.Unmarshal([]byte(line), &suppression))
+		suppressions = append(suppressions, suppression)
+	}
+
+	assert.Equal(t, []suppressionJson{
+		{
+			Filename:   "main.go",
+			Line:       5,
+			Kind:       "skipcq-file",
+			CheckerIds: []string{"go_filepath_clean_test"},
+			Owner:      "@security",
+			Until:      "2001-01-01",
+			Expired:    true,
+		},
+		{
+			Filename:   "main.go",
+			Line:       8,
+			Kind:       "skipcq",
+			CheckerIds: []string{},
+			Reason:     "input is trusted",
+		},
+	}, suppressions)
+
+	out.Reset()
+	require.NoError(t, c.ListSuppressions(&out, false))
+	assert.Contains(t, out.String(), "main.go:5")
+	assert.Contains(t, out.String(), "2001-01-01 (expired)")
+}
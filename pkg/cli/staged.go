@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"globstar.dev/analysis"
+)
+
+// parseStagedFiles parses the staged content of every file added or modified in the
+// git index. The parse results carry the path of the file in the working directory,
+// so the issues raised on them point to the staged file.
+func (c *Cli) parseStagedFiles() ([]*analysis.ParseResult, error) {
+	stagedFiles, err := c.GetStagedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*analysis.ParseResult
+	for path, content := range stagedFiles {
+		if c.isIgnoredPath(path) {
+			continue
+		}
+
+		language := analysis.LanguageFromFilePath(path)
+		grammar := language.Grammar()
+		if grammar == nil {
+			continue
+		}
+
+		file, err := analysis.Parse(path, content, language, grammar)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	slices.SortFunc(files, func(a, b *analysis.ParseResult) int {
+		return strings.Compare(a.FilePath, b.FilePath)
+	})
+
+	return files, nil
+}
+
+// isIgnoredPath reports whether path is excluded in the config, or is inside
+// one of the directories that are never analyzed.
+func (c *Cli) isIgnoredPath(path string) bool {
+	if c.Config.ShouldExcludePath(path) {
+		return true
+	}
+
+	rel, err := filepath.Rel(c.RootDirectory, path)
+	if err != nil {
+		return false
+	}
+
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+	for i, dir := range dirs {
+		if slices.Contains(defaultIgnoreDirs, dir) {
+			return true
+		}
+
+		if c.Config.ShouldExcludePath(filepath.Join(c.RootDirectory, filepath.Join(dirs[:i+1]...))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeFileContents writes the content of files under dir, at their path relative
+// to the root directory. Custom Go checkers run in a separate binary that reads files
+// from disk, so they analyze this copy of content that isn't on disk, like the staged one.
+func (c *Cli) writeFileContents(dir string, files []*analysis.ParseResult) error {
+	for _, file := range files {
+		rel, err := filepath.Rel(c.RootDirectory, file.FilePath)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(path, file.Source, 0o644); err != nil {
+			return fmt.Errorf("failed to write the content of %s: %w", rel, err)
+		}
+	}
+
+	return nil
+}
+
+// runCustomGoAnalyzersOnContent runs the custom Go checkers on the parsed content
+// of files, like their staged content, and maps the issues raised back to the files
+// in the working directory.
+func (c *Cli) runCustomGoAnalyzersOnContent(files []*analysis.ParseResult) ([]*analysis.Issue, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "globstar-content-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := c.writeFileContents(tmpDir, files); err != nil {
+		return nil, err
+	}
+
+	issues, _, err := c.runCustomGoAnalyzers(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		rel, err := filepath.Rel(tmpDir, issue.Filepath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		issue.Filepath = filepath.Join(c.RootDirectory, rel)
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaged(t *testing.T) {
+	c := newTestProject(t)
+	writeGoFile(t, c, "clean.go", "func clean() {}\n")
+
+	repo, err := git.PlainInit(c.RootDirectory, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	commitAll(t, worktree, "Initial commit")
+
+	c.Staged = true
+	require.NoError(t, c.RunCheckers(false, true), "nothing is staged")
+
+	// stage an issue, then fix it in the working directory only
+	writeGoFile(t, c, "clean.go", "func clean() {\n\t_ = filepath.Clean(\"x\")\n}\n")
+	_, err = worktree.Add("clean.go")
+	require.NoError(t, err)
+	writeGoFile(t, c, "clean.go", "func clean() {}\n")
+
+	result, err := c.collectIssues(false, true)
+	require.NoError(t, err)
+	require.Len(t, result.issues, 1)
+	assert.Equal(t, filepath.Join(c.RootDirectory, "clean.go"), result.issues[0].Filepath)
+	assert.Equal(t, uint32(5), result.issues[0].Range().StartPoint.Row)
+	assert.Equal(t, 1, result.numFilesChecked)
+
+	// unstaged issues are not reported
+	_, err = worktree.Add("clean.go")
+	require.NoError(t, err)
+	writeGoFile(t, c, "clean.go", "func clean() {\n\t_ = filepath.Clean(\"x\")\n}\n")
+	require.NoError(t, c.RunCheckers(false, true))
+}
+
+func TestInstallHook(t *testing.T) {
+	c := newTestProject(t)
+	_, err := git.PlainInit(c.RootDirectory, false)
+	require.NoError(t, err)
+
+	require.NoError(t, c.InstallHook(false))
+
+	hookPath := filepath.Join(c.RootDirectory, ".git", "hooks", "pre-commit")
+	info, err := os.Stat(hookPath)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100, "the hook should be executable")
+
+	content, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "globstar check --staged")
+
+	// re-installing replaces our own hook
+	require.NoError(t, c.InstallHook(false))
+
+	// but not somebody else's
+	require.NoError(t, os.WriteFile(hookPath, []byte("#!/bin/sh\nmake lint\n"), 0o755))
+	require.Error(t, c.InstallHook(false))
+	require.NoError(t, c.InstallHook(true))
+}
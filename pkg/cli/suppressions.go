@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"globstar.dev/analysis"
+)
+
+type suppressionJson struct {
+	Filename   string   `json:"filename"`
+	Line       int      `json:"line"`
+	Kind       string   `json:"kind"`
+	CheckerIds []string `json:"checkerIds"`
+	Reason     string   `json:"reason,omitempty"`
+	Owner      string   `json:"owner,omitempty"`
+	Until      string   `json:"until,omitempty"`
+	Expired    bool     `json:"expired"`
+}
+
+// ListSuppressions writes all skipcq comments in the project to w, with their location,
+// the checkers they apply to, and their reason, owner and expiry date.
+// With asJson set, each comment is written as a JSON object on its own line.
+func (c *Cli) ListSuppressions(w io.Writer, asJson bool) error {
+	files, err := analysis.ParseFiles(c.RootDirectory, func(path string) bool {
+		return !c.isIgnoredPath(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !asJson {
+		fmt.Fprintln(tw, "LOCATION\tKIND\tCHECKERS\tREASON\tOWNER\tUNTIL")
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		for _, comment := range file.SkipComments() {
+			suppression := suppressionJson{
+				Filename:   relativePath(c.RootDirectory, file.FilePath),
+				Line:       comment.CommentLine + 1,
+				Kind:       string(comment.Kind),
+				CheckerIds: comment.CheckerIds,
+				Reason:     comment.Reason,
+				Owner:      comment.Owner,
+				Until:      comment.Until,
+				Expired:    comment.Expired(now),
+			}
+
+			if asJson {
+				if suppression.CheckerIds == nil {
+					suppression.CheckerIds = []string{}
+				}
+
+				out, err := json.Marshal(suppression)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				continue
+			}
+
+			checkerIds := "all"
+			if len(suppression.CheckerIds) > 0 {
+				checkerIds = strings.Join(suppression.CheckerIds, ",")
+			}
+
+			until := suppression.Until
+			if suppression.Expired {
+				until += " (expired)"
+			}
+
+			fmt.Fprintf(tw, "%s:%d\t%s\t%s\t%s\t%s\t%s\n",
+				suppression.Filename,
+				suppression.Line,
+				suppression.Kind,
+				checkerIds,
+				valueOrDash(suppression.Reason),
+				valueOrDash(suppression.Owner),
+				valueOrDash(until),
+			)
+		}
+	}
+
+	if asJson {
+		return nil
+	}
+
+	return tw.Flush()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
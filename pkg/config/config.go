@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/gobwas/glob"
 	"gopkg.in/yaml.v3"
@@ -44,12 +47,75 @@ func (c Category) IsValid() bool {
 }
 
 type FailureConfig struct {
-	ExitCode   int                 `yaml:"exitCode"`
-	SeverityIn []Severity          `yaml:"severityIn"`
-	CategoryIn []Category          `yaml:"categoryIn"`
+	ExitCode   int        `yaml:"exitCode"`
+	SeverityIn []Severity `yaml:"severityIn"`
+	CategoryIn []Category `yaml:"categoryIn"`
+	// MetadataIn fails the check on the issues of checkers whose metadata has all
+	// the values of one of the entries, like {cwe: CWE-79, confidence: high}
 	MetadataIn []map[string]string `yaml:"metadataIn"`
 }
 
+// MatchesMetadata reports whether the metadata of a checker, by selector key,
+// matches one of the entries of metadataIn.
+func (fc *FailureConfig) MatchesMetadata(metadata map[string][]string) bool {
+	for _, entry := range fc.MetadataIn {
+		if len(entry) == 0 {
+			continue
+		}
+
+		matches := true
+		for key, value := range entry {
+			if !metadataHas(metadata, key, value) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// metadataKeys are the keys of the checker metadata that selectors can match
+var metadataKeys = []string{"cwe", "owasp", "confidence", "tag", "author", "version"}
+
+// parseSelector splits a selector on the metadata of checkers, like "cwe:CWE-79"
+// or "tag:crypto", into its key and value. Checker IDs aren't selectors.
+func parseSelector(s string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(s, ":")
+	if !ok {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), true
+}
+
+// metadataHas reports whether the metadata has value for key. Values are compared
+// without case, and a CWE can be written without the "CWE-" prefix.
+func metadataHas(metadata map[string][]string, key, value string) bool {
+	key = strings.ToLower(key)
+	if key == "cwe" && !strings.HasPrefix(strings.ToUpper(value), "CWE-") {
+		value = "CWE-" + value
+	}
+
+	for _, v := range metadata[key] {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// selects reports whether an entry of enabledCheckers or disabledCheckers
+// selects the checker with the given ID and metadata.
+func selects(entry, id string, metadata map[string][]string) bool {
+	if key, value, ok := parseSelector(entry); ok {
+		return metadataHas(metadata, key, value)
+	}
+	return entry == id
+}
+
 func (fc *FailureConfig) PopulateDefaults() {
 	if fc.ExitCode == 0 {
 		fc.ExitCode = 1
@@ -64,13 +130,97 @@ func (fc *FailureConfig) PopulateDefaults() {
 	}
 }
 
+// IgnoreRule silences the issues of some checkers in some files, for code that
+// can't have skipcq comments, like vendored or generated files.
+// An issue is ignored when it matches all the fields that are set.
+type IgnoreRule struct {
+	// Checkers are globs of the checker IDs the rule applies to
+	Checkers []string `yaml:"checkers"`
+	// Paths are globs of the file paths, relative to the root directory, the rule applies to
+	Paths []string `yaml:"paths"`
+	// Match is a regular expression the code an issue is raised on must match
+	Match string `yaml:"match"`
+
+	checkerGlobs []glob.Glob
+	pathGlobs    []glob.Glob
+	matchRegexp  *regexp.Regexp
+	compiled     bool
+}
+
+func (rule *IgnoreRule) compile() error {
+	if len(rule.Checkers) == 0 && len(rule.Paths) == 0 && rule.Match == "" {
+		return fmt.Errorf("ignore rules must set at least one of checkers, paths or match")
+	}
+
+	rule.checkerGlobs = nil
+	for _, pattern := range rule.Checkers {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid checker pattern %s in ignore rule: %w", pattern, err)
+		}
+		rule.checkerGlobs = append(rule.checkerGlobs, g)
+	}
+
+	rule.pathGlobs = nil
+	for _, pattern := range rule.Paths {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return fmt.Errorf("invalid path pattern %s in ignore rule: %w", pattern, err)
+		}
+		rule.pathGlobs = append(rule.pathGlobs, g)
+	}
+
+	rule.matchRegexp = nil
+	if rule.Match != "" {
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match regex %s in ignore rule: %w", rule.Match, err)
+		}
+		rule.matchRegexp = re
+	}
+
+	rule.compiled = true
+	return nil
+}
+
+// Matches reports whether the rule ignores the issue raised by checkerId in the
+// file at relPath (slash separated), on the code text.
+func (rule *IgnoreRule) Matches(checkerId, relPath, text string) bool {
+	// rules that weren't loaded from a config file are compiled on first use
+	if !rule.compiled && rule.compile() != nil {
+		return false
+	}
+
+	if len(rule.checkerGlobs) > 0 && !matchesAny(rule.checkerGlobs, checkerId) {
+		return false
+	}
+
+	if len(rule.pathGlobs) > 0 && !matchesAny(rule.pathGlobs, relPath) {
+		return false
+	}
+
+	return rule.matchRegexp == nil || rule.matchRegexp.MatchString(text)
+}
+
+func matchesAny(globs []glob.Glob, s string) bool {
+	for _, g := range globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
 type Config struct {
-	CheckerDir       string        `yaml:"checkerDir"`
-	EnabledCheckers  []string      `yaml:"enabledCheckers"`
-	DisabledCheckers []string      `yaml:"disabledCheckers"`
-	TargetDirs       []string      `yaml:"targetDirs"`
-	ExcludePatterns  []string      `yaml:"excludePatterns"`
-	FailWhen         FailureConfig `yaml:"failWhen"`
+	CheckerDir          string        `yaml:"checkerDir"`
+	EnabledCheckers     []string      `yaml:"enabledCheckers"`
+	DisabledCheckers    []string      `yaml:"disabledCheckers"`
+	TargetDirs          []string      `yaml:"targetDirs"`
+	ExcludePatterns     []string      `yaml:"excludePatterns"`
+	FailWhen            FailureConfig `yaml:"failWhen"`
+	ReportUnusedSkipcq  bool          `yaml:"reportUnusedSkipcq"`
+	RequireSkipcqReason []Category    `yaml:"requireSkipcqReason"`
+	Ignore              []IgnoreRule  `yaml:"ignore"`
 
 	excludedGlobs []glob.Glob
 }
@@ -115,6 +265,21 @@ func (config *Config) Validate() error {
 	if err := config.validateFailureConfig(); err != nil {
 		return err
 	}
+	for _, entry := range append(slices.Clone(config.EnabledCheckers), config.DisabledCheckers...) {
+		if key, _, ok := parseSelector(entry); ok && !slices.Contains(metadataKeys, key) {
+			return fmt.Errorf("invalid checker selector %s, the key must be one of %s", entry, strings.Join(metadataKeys, ", "))
+		}
+	}
+	for _, category := range config.RequireSkipcqReason {
+		if !category.IsValid() {
+			return fmt.Errorf("invalid category in requireSkipcqReason: %s", category)
+		}
+	}
+	for i := range config.Ignore {
+		if err := config.Ignore[i].compile(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -147,6 +312,14 @@ func (config *Config) validateFailureConfig() error {
 		}
 	}
 
+	for _, entry := range config.FailWhen.MetadataIn {
+		for key := range entry {
+			if !slices.Contains(metadataKeys, strings.ToLower(key)) {
+				return fmt.Errorf("invalid metadata key in metadataIn: %s, must be one of %s", key, strings.Join(metadataKeys, ", "))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -159,6 +332,20 @@ func (config *Config) ShouldExcludePath(path string) bool {
 	return false
 }
 
+// IsCheckerEnabled reports whether the checker with the given ID and metadata should run.
+// The metadata has the values of the checker's metadata by selector key, like "cwe".
+// When enabledCheckers is set, only the checkers selected by it are enabled.
+// Entries are checker IDs, or selectors on the metadata like "cwe:CWE-79" and "tag:crypto".
+func (config *Config) IsCheckerEnabled(id string, metadata map[string][]string) bool {
+	selected := func(entry string) bool { return selects(entry, id, metadata) }
+
+	if len(config.EnabledCheckers) > 0 && !slices.ContainsFunc(config.EnabledCheckers, selected) {
+		return false
+	}
+
+	return !slices.ContainsFunc(config.DisabledCheckers, selected)
+}
+
 func (config *Config) AddExcludePatterns(patterns ...string) error {
 	config.ExcludePatterns = append(config.ExcludePatterns, patterns...)
 